@@ -0,0 +1,81 @@
+; checker.gb - 8x8 tile checkerboard on the background with the window
+; (WX=87, WY=72) covering the bottom right quarter in colour 1
+;
+; checker.png is generated from the layout, not the emulator:
+;   x >= 80 && y >= 72      -> $AA (window, colour 1)
+;   (x/8 + y/8) is odd      -> $00 (tile 1, colour 3)
+;   otherwise               -> $FF (tile 0, colour 0)
+;
+; Build with rgbds: rgbasm -o checker.o checker.asm && rgblink -o checker.gb checker.o && rgbfix -v -p 0 -t CHECKER checker.gb
+
+SECTION "entry", ROM0[$100]
+	nop
+	jp Start
+
+SECTION "main", ROM0[$150]
+Start:
+	di
+	ld sp, $FFFE
+.waitVBlank
+	ldh a, [$44]                ; LY
+	cp 144
+	jr c, .waitVBlank
+	xor a
+	ldh [$40], a                ; LCD off
+
+	ld de, Tiles
+	ld hl, $8000
+	ld bc, 48
+	call Copy
+	ld de, BGMap
+	ld hl, $9800
+	ld bc, $400
+	call Copy
+	ld de, WinMap
+	ld hl, $9C00
+	ld bc, $400
+	call Copy
+
+	ld a, %11100100
+	ldh [$47], a                ; BGP
+	xor a
+	ldh [$42], a                ; SCY
+	ldh [$43], a                ; SCX
+	ld a, 72
+	ldh [$4A], a                ; WY
+	ld a, 87
+	ldh [$4B], a                ; WX
+	ld a, %11110001             ; LCD on, window map $9C00, window on, tiles $8000, BG on
+	ldh [$40], a
+	jr @
+
+; Copy copy bc bytes from de to hl
+Copy:
+	ld a, [de]
+	ld [hli], a
+	inc de
+	dec bc
+	ld a, b
+	or c
+	jr nz, Copy
+	ret
+
+SECTION "tiles", ROM0[$200]
+Tiles:
+	ds 16, $00                  ; tile 0, colour 0
+	ds 16, $FF                  ; tile 1, colour 3
+	REPT 8                      ; tile 2, colour 1
+	db $FF, $00
+	ENDR
+
+SECTION "bg map", ROM0[$400]
+BGMap:
+FOR ROW, 32
+	FOR COL, 32
+		db (ROW + COL) & 1
+	ENDR
+ENDR
+
+SECTION "window map", ROM0[$800]
+WinMap:
+	ds $400, 2
@@ -0,0 +1,157 @@
+package cartridge
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+)
+
+// maxROMSize the most that's read from a rom or unpacked from an archive, twice
+// the largest size the header allows so oversized roms still get a warning
+const maxROMSize = 0x1000000
+
+var (
+	zipMagic  = []byte("PK\x03\x04")
+	gzipMagic = []byte{0x1F, 0x8B}
+)
+
+// LoadROMFrom load a rom of size bytes from r, zip and gzip archives are unpacked
+func LoadROMFrom(r io.ReaderAt, size int64, opts LoadOptions) (*Cartridge, error) {
+	rom, err := ReadROM(r, size, opts)
+	if err != nil {
+		return nil, err
+	}
+	return LoadROM(rom, opts)
+}
+
+// ReadROMFile read the rom at path (- for stdin), zip and gzip archives are unpacked
+// Only opts.Entry is used, the rom isn't loaded
+func ReadROMFile(path string, opts LoadOptions) ([]byte, error) {
+	if path == "-" { // stdin can't be read at an offset so read it all first
+		data, err := readLimited(os.Stdin)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to read rom from stdin: %v", err)
+		}
+		return ReadROM(bytes.NewReader(data), int64(len(data)), opts)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	return ReadROM(file, info.Size(), opts)
+}
+
+// ReadROM read a rom of size bytes from r, unpacking it if it's a zip or gzip
+// archive. Archives can be any size, only what's unpacked from them is limited.
+// opts.Entry picks the file to unpack from a zip
+func ReadROM(r io.ReaderAt, size int64, opts LoadOptions) ([]byte, error) {
+	magic := make([]byte, len(zipMagic))
+	n, err := r.ReadAt(magic, 0)
+	if err != nil && err != io.EOF {
+		return nil, fmt.Errorf("Failed to read rom: %v", err)
+	}
+	magic = magic[:n]
+
+	switch {
+	case bytes.HasPrefix(magic, zipMagic):
+		return readZip(r, size, opts.Entry)
+	case bytes.HasPrefix(magic, gzipMagic):
+		return readGzip(io.NewSectionReader(r, 0, size))
+	}
+
+	rom, err := readLimited(io.NewSectionReader(r, 0, size))
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read rom: %v", err)
+	}
+	return rom, nil
+}
+
+// readLimited read all of r, failing if there's more than maxROMSize
+func readLimited(r io.Reader) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, maxROMSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxROMSize {
+		return nil, fmt.Errorf("larger than %d bytes", maxROMSize)
+	}
+	return data, nil
+}
+
+// readZip unpack the rom from the zip in r, either the file called name or the first .gb or .gbc
+func readZip(r io.ReaderAt, size int64, name string) ([]byte, error) {
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to open zip: %v", err)
+	}
+
+	entry := findZipEntry(archive.File, name)
+	if entry == nil {
+		if name != "" {
+			return nil, fmt.Errorf("Failed to find %q in zip", name)
+		}
+		return nil, fmt.Errorf("Failed to find a .gb or .gbc file in zip")
+	}
+
+	file, err := entry.Open()
+	if err != nil {
+		return nil, fmt.Errorf("Failed to open %s in zip: %v", entry.Name, err)
+	}
+	defer file.Close()
+
+	rom, err := readLimited(file)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to unzip %s: %v", entry.Name, err)
+	}
+	return rom, nil
+}
+
+// findZipEntry find the file called name (by full path or just the file name), or
+// the first .gb or .gbc if name is empty. nil is returned if there isn't one
+func findZipEntry(files []*zip.File, name string) *zip.File {
+	for _, file := range files {
+		if file.FileInfo().IsDir() {
+			continue
+		}
+
+		if name != "" {
+			if file.Name == name || path.Base(file.Name) == name {
+				return file
+			}
+			continue
+		}
+
+		switch strings.ToLower(path.Ext(file.Name)) {
+		case ".gb", ".gbc":
+			return file
+		}
+	}
+	return nil
+}
+
+// readGzip unpack a gzipped rom
+func readGzip(r io.Reader) ([]byte, error) {
+	reader, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to open gzip: %v", err)
+	}
+	defer reader.Close()
+
+	rom, err := readLimited(reader)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to gunzip rom: %v", err)
+	}
+	return rom, nil
+}
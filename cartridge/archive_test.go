@@ -0,0 +1,96 @@
+package cartridge
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"testing"
+)
+
+// zipped zip files with the given names and contents
+func zipped(t *testing.T, files ...string) []byte {
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	for i := 0; i+1 < len(files); i += 2 {
+		w, err := archive.Create(files[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(files[i+1]))
+	}
+	if err := archive.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestReadROM(t *testing.T) {
+	var gzipped bytes.Buffer
+	w := gzip.NewWriter(&gzipped)
+	w.Write([]byte("gzipped rom"))
+	w.Close()
+
+	archive := zipped(t, "readme.txt", "not a rom", "roms/game.GB", "first rom", "roms/other.gbc", "second rom")
+
+	tests := []struct {
+		name  string
+		data  []byte
+		entry string
+		want  string
+	}{
+		{"plain", []byte("plain rom"), "", "plain rom"},
+		{"gzip", gzipped.Bytes(), "", "gzipped rom"},
+		{"zip first rom", archive, "", "first rom"},
+		{"zip entry by name", archive, "other.gbc", "second rom"},
+		{"zip entry by path", archive, "readme.txt", "not a rom"},
+	}
+
+	for _, test := range tests {
+		rom, err := ReadROM(bytes.NewReader(test.data), int64(len(test.data)), LoadOptions{Entry: test.entry})
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+		} else if string(rom) != test.want {
+			t.Errorf("%s: got %q, want %q", test.name, rom, test.want)
+		}
+	}
+
+	if _, err := ReadROM(bytes.NewReader(archive), int64(len(archive)), LoadOptions{Entry: "missing.gb"}); err == nil {
+		t.Error("missing entry loaded")
+	}
+	noROMs := zipped(t, "readme.txt", "no roms")
+	if _, err := ReadROM(bytes.NewReader(noROMs), int64(len(noROMs)), LoadOptions{}); err == nil {
+		t.Error("zip without a rom loaded")
+	}
+}
+
+func TestReadROMLargeZip(t *testing.T) {
+	// a rom pack bigger than maxROMSize only has the chosen rom unpacked
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	for _, file := range []struct {
+		name string
+		size int
+	}{{"big.bin", maxROMSize + 1}, {"game.gb", 0x8000}} {
+		w, err := archive.CreateHeader(&zip.FileHeader{Name: file.name, Method: zip.Store})
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write(make([]byte, file.size))
+	}
+	if err := archive.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	rom, err := ReadROM(bytes.NewReader(buf.Bytes()), int64(buf.Len()), LoadOptions{})
+	if err != nil {
+		t.Fatalf("Failed to read rom from a large zip: %v", err)
+	}
+	if len(rom) != 0x8000 {
+		t.Errorf("Got %d bytes, want 0x8000", len(rom))
+	}
+
+	big := LoadOptions{Entry: "big.bin"}
+	if _, err := ReadROM(bytes.NewReader(buf.Bytes()), int64(buf.Len()), big); err == nil {
+		t.Error("Entry larger than maxROMSize loaded")
+	}
+}
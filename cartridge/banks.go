@@ -0,0 +1,118 @@
+package cartridge
+
+import (
+	"fmt"
+	"io"
+	"math/bits"
+)
+
+// banks memory split into equal sized banks shared by all the mappers. There's
+// always a power of 2 banks so bank numbers can be masked to the bank count the
+// same way the unconnected address lines on a real cart wrap them, and offsets
+// wrap inside a bank so chips smaller than their window are mirrored across it
+type banks struct {
+	data [][]byte
+	mask int // the number of banks - 1
+}
+
+// nextPowerOf2 round n up to a power of 2
+func nextPowerOf2(n int) int {
+	if n <= 1 {
+		return 1
+	}
+	return 1 << bits.Len(uint(n-1))
+}
+
+// newROMBanks split rom into banks of bankSize. size is the size the header
+// gives, it's rounded up to a power of 2 and anything past the end of rom reads
+// as 0xFF like an unconnected bus
+func newROMBanks(rom []byte, size, bankSize int) banks {
+	size = nextPowerOf2(max(size, bankSize))
+	b := banks{data: make([][]byte, size/bankSize), mask: size/bankSize - 1}
+
+	for i := range b.data {
+		start, end := i*bankSize, (i+1)*bankSize
+		if end <= len(rom) { // no need to copy a whole bank
+			b.data[i] = rom[start:end:end]
+			continue
+		}
+
+		bank := make([]byte, bankSize)
+		for j := range bank {
+			bank[j] = 0xFF
+		}
+		if start < len(rom) {
+			copy(bank, rom[start:])
+		}
+		b.data[i] = bank
+	}
+
+	return b
+}
+
+// newRAMBanks create size bytes of ram split into banks of bankSize, ram smaller
+// than a bank is a single bank of that size. No ram gives no banks
+func newRAMBanks(size, bankSize int) banks {
+	if size <= 0 {
+		return banks{}
+	}
+
+	size = nextPowerOf2(size)
+	bankSize = min(bankSize, size)
+	b := banks{data: make([][]byte, size/bankSize), mask: size/bankSize - 1}
+	for i := range b.data {
+		b.data[i] = make([]byte, bankSize)
+	}
+	return b
+}
+
+// empty return whether there aren't any banks
+func (b *banks) empty() bool {
+	return len(b.data) == 0
+}
+
+// count return the number of banks
+func (b *banks) count() int {
+	return len(b.data)
+}
+
+// bank get bank n, masked to the number of banks
+func (b *banks) bank(n int) []byte {
+	return b.data[n&b.mask]
+}
+
+// read read the byte at offset in bank
+func (b *banks) read(bank int, offset uint16) byte {
+	data := b.bank(bank)
+	return data[int(offset)&(len(data)-1)]
+}
+
+// write write data to offset in bank
+// Returns whether the byte changed
+func (b *banks) write(bank int, offset uint16, data byte) bool {
+	bankData := b.bank(bank)
+	offset &= uint16(len(bankData) - 1)
+	changed := bankData[offset] != data
+	bankData[offset] = data
+	return changed
+}
+
+// save write every bank to file in order
+func (b *banks) save(file io.Writer) error {
+	for _, bank := range b.data {
+		if _, err := file.Write(bank); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// load fill every bank in order from file
+func (b *banks) load(file io.Reader) error {
+	for i, bank := range b.data {
+		if _, err := io.ReadFull(file, bank); err != nil {
+			return fmt.Errorf("Failed to read bank %d: %v", i, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package cartridge
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestROMBanksPadding(t *testing.T) {
+	// 3 banks of rom is padded to 4 and the missing bank reads as 0xFF
+	rom := newROMBanks(bankedROM(3), 3*0x4000, 0x4000)
+	if rom.count() != 4 {
+		t.Fatalf("got %d banks, want 4", rom.count())
+	}
+	if got := rom.read(3, 0); got != 0xFF {
+		t.Errorf("padding read 0x%02X, want 0xFF", got)
+	}
+	if got := rom.read(6, 0); got != 2 {
+		t.Errorf("bank 6 read bank %d, want it masked to bank 2", got)
+	}
+}
+
+func TestRAMBanksMirroring(t *testing.T) {
+	ram := newRAMBanks(0x800, 0x2000) // 2KiB of ram in an 8KiB window
+	ram.write(0, 0x0010, 0x42)
+	if got := ram.read(3, 0x1810); got != 0x42 {
+		t.Errorf("mirror read 0x%02X, want 0x42", got)
+	}
+
+	var save bytes.Buffer
+	if err := ram.save(&save); err != nil || save.Len() != 0x800 {
+		t.Fatalf("saved %d bytes (%v), want 0x800", save.Len(), err)
+	}
+}
+
+// mapperConstructors every mapper built from a rom that's shorter than the header
+// says and isn't a power of 2 in size
+var mapperConstructors = map[string]func(rom []byte, romSize int) MemoryBankController{
+	"MBC0":   func(rom []byte, romSize int) MemoryBankController { return NewMBC0(rom, true, 0x2000) },
+	"MBC1":   func(rom []byte, romSize int) MemoryBankController { return NewMBC1(rom, romSize, 0x8000, true) },
+	"MBC2":   func(rom []byte, romSize int) MemoryBankController { return NewMBC2(rom, romSize, true) },
+	"MBC3":   func(rom []byte, romSize int) MemoryBankController { return NewMBC3(rom, true, true, 0x10000, romSize) },
+	"MBC5":   func(rom []byte, romSize int) MemoryBankController { return NewMBC5(rom, true, true, 0x2000, romSize) },
+	"MBC6":   func(rom []byte, romSize int) MemoryBankController { return NewMBC6(rom, true, romSize) },
+	"MBC7":   func(rom []byte, romSize int) MemoryBankController { return NewMBC7(rom, true, romSize) },
+	"MMM01":  func(rom []byte, romSize int) MemoryBankController { return NewMMM01(rom, true, 0x800, romSize) },
+	"HuC1":   func(rom []byte, romSize int) MemoryBankController { return NewHuC1(rom, true, 0x8000, romSize) },
+	"HuC3":   func(rom []byte, romSize int) MemoryBankController { return NewHuC3(rom, true, 0x2000, romSize) },
+	"Camera": func(rom []byte, romSize int) MemoryBankController { return NewPocketCamera(rom, true, romSize) },
+	"TAMA5":  func(rom []byte, romSize int) MemoryBankController { return NewTAMA5(rom, romSize) },
+}
+
+// FuzzMapperWrites write arbitrary values to arbitrary addresses of every mapper
+// and read the whole cart area back after each, none of it should panic
+func FuzzMapperWrites(f *testing.F) {
+	f.Add([]byte{0x20, 0x00, 0xFF, 0x40, 0x00, 0x03, 0x60, 0x00, 0x01, 0x00, 0x00, 0x0A})
+	f.Add([]byte{0x00, 0x00, 0x4A, 0x30, 0x00, 0x01, 0x40, 0x00, 0x7F, 0xA0, 0x01, 0x0E})
+
+	f.Fuzz(func(t *testing.T, writes []byte) {
+		rom := bankedROM(3)
+		for name, newMapper := range mapperConstructors {
+			mbc := newMapper(rom, 8*0x4000)
+			for i := 0; i+2 < len(writes); i += 3 {
+				mbc.WriteByte(uint16(writes[i])<<8|uint16(writes[i+1]), writes[i+2])
+				for addr := 0; addr < 0xC000; addr += 0x00FF {
+					mbc.ReadByte(uint16(addr))
+				}
+			}
+
+			var save bytes.Buffer
+			if err := mbc.SaveFile(&save); err != nil {
+				t.Errorf("%s: failed to save: %v", name, err)
+			}
+		}
+	})
+}
@@ -0,0 +1,206 @@
+package cartridge
+
+import (
+	"image"
+	"image/color"
+	"io"
+)
+
+////////////////////////
+// Pocket Camera Cart //
+////////////////////////
+//
+// Has 128KiB of ram and the camera sensor, setting bit 4 of the ram bank maps
+// the sensor registers to 0xA000-0xBFFF instead. Writing bit 0 of register 0
+// takes a photo, which is dithered into 2bpp tiles at 0xA100 in ram bank 0.
+//
+// The photo comes from Source and is taken straight away. Only the dither
+// matrix is emulated, the exposure, gain and edge enhancement registers are ignored.
+
+const (
+	CameraWidth, CameraHeight = 128, 112 // the size of the photos the camera takes
+
+	cameraRAMSize   = 0x20000
+	cameraRegisters = 0x36   // the number of sensor registers
+	cameraImageAddr = 0x0100 // where in ram bank 0 the photo goes
+	cameraMatrix    = 0x06   // the first register of the 4x4 dither matrix (3 thresholds for each pixel)
+)
+
+type PocketCamera struct {
+	rom        banks // every rom bank including bank 0
+	romBank    byte  // the active rom bank (6 bits)
+	ram        banks
+	ramBank    byte // the active ram bank (4 bits)
+	ramEnabled bool // whether ram writes work, reading always does
+	regsMapped bool // whether the sensor registers are mapped instead of the ram
+	regs       [cameraRegisters]byte
+	hasBattery bool
+
+	Source func() image.Image // get the picture for the camera to take, a test pattern is used if nil
+}
+
+// LoadFile implements MemoryBankController.
+func (m *PocketCamera) LoadFile(file io.Reader) error {
+	if !m.hasBattery {
+		return nil
+	}
+	return m.ram.load(file)
+}
+
+// SaveFile implements MemoryBankController.
+func (m *PocketCamera) SaveFile(file io.Writer) error {
+	if !m.hasBattery {
+		return nil
+	}
+
+	return m.ram.save(file)
+}
+
+// NewPocketCamera create and return a new Pocket Camera
+func NewPocketCamera(rom []byte, hasBattery bool, romSize int) *PocketCamera {
+	mbc := new(PocketCamera)
+	mbc.hasBattery = hasBattery
+	mbc.rom = newROMBanks(rom, romSize, 0x4000)
+	mbc.romBank = 1
+	mbc.ram = newRAMBanks(cameraRAMSize, 0x2000)
+	return mbc
+}
+
+// ReadByte read byte from addr
+func (m *PocketCamera) ReadByte(addr uint16) byte {
+	switch {
+	case addr <= 0x3FFF:
+		return m.rom.read(0, addr)
+
+	case addr >= 0x4000 && addr <= 0x7FFF:
+		return m.rom.read(int(m.romBank), addr-0x4000)
+
+	case addr >= 0xA000 && addr <= 0xBFFF:
+		if !m.regsMapped {
+			return m.ram.read(int(m.ramBank), addr-0xA000)
+		}
+		if addr&0x7F == 0 { // only the capture register can be read
+			return m.regs[0]
+		}
+		return 0x00
+	}
+
+	return 0xFF
+}
+
+// WriteByte write data to addr
+// Returns whether the save changed
+func (m *PocketCamera) WriteByte(addr uint16, data byte) bool {
+	switch {
+	case addr <= 0x1FFF:
+		m.ramEnabled = data&0x0F == 0x0A
+
+	case addr >= 0x2000 && addr <= 0x3FFF:
+		m.switchROMBank(int(data))
+
+	case addr >= 0x4000 && addr <= 0x5FFF:
+		m.regsMapped = data&0x10 != 0
+		m.switchRAMBank(int(data))
+
+	case addr >= 0xA000 && addr <= 0xBFFF:
+		if !m.regsMapped {
+			if m.ramEnabled {
+				return m.ram.write(int(m.ramBank), addr-0xA000, data)
+			}
+			return false
+		}
+
+		reg := int(addr & 0x7F) // the registers are mirrored every 0x80 bytes
+		if reg >= cameraRegisters {
+			return false
+		}
+		m.regs[reg] = data
+		if reg == 0 && data&0x01 != 0 {
+			m.capture()
+		}
+	}
+	return false
+}
+
+// capture take a photo from Source and dither it into ram bank 0
+func (m *PocketCamera) capture() {
+	var src image.Image
+	if m.Source != nil {
+		src = m.Source()
+	}
+	if src == nil {
+		src = cameraTestPattern()
+	}
+
+	bounds := src.Bounds()
+	ram := m.ram.bank(0)[cameraImageAddr:]
+	for y := 0; y < CameraHeight; y++ {
+		for x := 0; x < CameraWidth; x++ {
+			// nearest neighbour scale to the sensor size
+			srcX := bounds.Min.X + x*bounds.Dx()/CameraWidth
+			srcY := bounds.Min.Y + y*bounds.Dy()/CameraHeight
+			gray := color.GrayModel.Convert(src.At(srcX, srcY)).(color.Gray).Y
+
+			shade := m.dither(x, y, gray)
+			tile := (y/8)*(CameraWidth/8) + x/8
+			offset := tile*16 + (y%8)*2
+			bit := byte(0x80) >> (x % 8)
+			if shade&0x01 != 0 {
+				ram[offset] |= bit
+			} else {
+				ram[offset] &^= bit
+			}
+			if shade&0x02 != 0 {
+				ram[offset+1] |= bit
+			} else {
+				ram[offset+1] &^= bit
+			}
+		}
+	}
+
+	m.regs[0] &^= 0x01 // done capturing
+}
+
+// dither get the shade (0 white - 3 black) for a pixel with the thresholds from the dither matrix
+func (m *PocketCamera) dither(x, y int, gray byte) byte {
+	thresholds := m.regs[cameraMatrix+((y%4)*4+x%4)*3:]
+	switch {
+	case gray < thresholds[0]:
+		return 3
+	case gray < thresholds[1]:
+		return 2
+	case gray < thresholds[2]:
+		return 1
+	}
+	return 0
+}
+
+// cameraTestPattern a gradient with a dark square in the middle, used when there's no source
+func cameraTestPattern() image.Image {
+	img := image.NewGray(image.Rect(0, 0, CameraWidth, CameraHeight))
+	for y := 0; y < CameraHeight; y++ {
+		for x := 0; x < CameraWidth; x++ {
+			shade := byte(x * 255 / (CameraWidth - 1))
+			if x >= 40 && x < 88 && y >= 32 && y < 80 {
+				shade = 255 - shade
+			}
+			img.SetGray(x, y, color.Gray{Y: shade})
+		}
+	}
+	return img
+}
+
+// switchROMBank switch to the rom bank in the lower 6 bits of bank, 0 can be mapped
+func (m *PocketCamera) switchROMBank(bank int) {
+	m.romBank = byte(bank & 0x3F)
+}
+
+// switchRAMBank switch to the ram bank in the lower 4 bits of bank
+func (m *PocketCamera) switchRAMBank(bank int) {
+	m.ramBank = byte(bank & 0x0F)
+}
+
+// HasBattery return whether or not MBC supports battery
+func (m *PocketCamera) HasBattery() bool {
+	return m.hasBattery
+}
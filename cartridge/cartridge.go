@@ -3,10 +3,21 @@ package cartridge
 import (
 	"bytes"
 	"fmt"
-	"log"
+	"hash/crc32"
 	"strings"
 )
 
+const headerEnd = 0x0150 // the rom has to at least reach the end of the header
+
+// LoadOptions how a rom is loaded
+type LoadOptions struct {
+	// Strict whether problems with the rom that can be worked around (bad checksums,
+	// wrong sizes and invalid size codes) stop it from loading instead of being warnings
+	Strict bool
+	// Entry the file to load from a zip, empty loads the first .gb or .gbc in it
+	Entry string
+}
+
 // Memory bank type constants - mapped to their equivalent value in rom[0x0147]
 const (
 	MBC_0                    = 0x00
@@ -28,9 +39,17 @@ const (
 	MBC_5_RUMBLE_RAM_BATTERY = 0x1E
 	MBC_6                    = 0x20
 
-	//cbf doing rest
-	//ignoring the rom and ram options as they aren't used?
-	// TODO add the MMM01 stuff - excluding for now as they aren't really used
+	// the less common mappers
+	ROM_RAM                         = 0x08
+	ROM_RAM_BATTERY                 = 0x09
+	MMM01                           = 0x0B
+	MMM01_RAM                       = 0x0C
+	MMM01_RAM_BATTERY               = 0x0D
+	MBC_7_SENSOR_RUMBLE_RAM_BATTERY = 0x22
+	POCKET_CAMERA                   = 0xFC
+	BANDAI_TAMA5                    = 0xFD
+	HUC3                            = 0xFE
+	HUC1_RAM_BATTERY                = 0xFF
 )
 
 var CartTypes map[byte]CartType = map[byte]CartType{
@@ -52,6 +71,17 @@ var CartTypes map[byte]CartType = map[byte]CartType{
 	MBC_5_RUMBLE_RAM:         CartType{MBC_5_RUMBLE_RAM, "MBC5+RUMBLE+RAM"},
 	MBC_5_RUMBLE_RAM_BATTERY: CartType{MBC_5_RUMBLE_RAM_BATTERY, "MBC5+RUMBLE+RAM+BATTERY"},
 	MBC_6:                    CartType{MBC_6, "MBC6"},
+
+	ROM_RAM:                         CartType{ROM_RAM, "ROM+RAM"},
+	ROM_RAM_BATTERY:                 CartType{ROM_RAM_BATTERY, "ROM+RAM+BATTERY"},
+	MMM01:                           CartType{MMM01, "MMM01"},
+	MMM01_RAM:                       CartType{MMM01_RAM, "MMM01+RAM"},
+	MMM01_RAM_BATTERY:               CartType{MMM01_RAM_BATTERY, "MMM01+RAM+BATTERY"},
+	MBC_7_SENSOR_RUMBLE_RAM_BATTERY: CartType{MBC_7_SENSOR_RUMBLE_RAM_BATTERY, "MBC7+SENSOR+RUMBLE+RAM+BATTERY"},
+	POCKET_CAMERA:                   CartType{POCKET_CAMERA, "POCKET CAMERA"},
+	BANDAI_TAMA5:                    CartType{BANDAI_TAMA5, "BANDAI TAMA5"},
+	HUC3:                            CartType{HUC3, "HuC3"},
+	HUC1_RAM_BATTERY:                CartType{HUC1_RAM_BATTERY, "HuC1+RAM+BATTERY"},
 }
 
 type CartType struct {
@@ -65,6 +95,7 @@ type Cartridge struct {
 	RAMSize       int
 	ROMSize       int
 	HasCGBSupport bool // whether the CGB flag is set -> fonud in rom[0x0143]
+	HasSGBSupport bool // whether the SGB flag is set -> found in rom[0x0146], also needs the old licensee code to be 33
 	RomType       byte
 	Type          CartType
 
@@ -73,8 +104,17 @@ type Cartridge struct {
 	
 	IsJapanese bool
 	OldLicenseeCode byte // the old licensee code, if 33 then use new licensee code
-	NewLicenseeCode byte // the new licensee code, only used if OldLicenseeCode is 33
-	
+	NewLicenseeCode string // the new licensee code, only used if OldLicenseeCode is 33
+
+	Warnings []error // problems with the rom that were worked around (these are errors when loading strictly)
+	strict   bool    // whether problems with the rom are errors instead of warnings
+	RAMDirty bool // whether the save (ram, flash etc) has changed since it was last saved
+}
+
+// SaveName get the save file name for the game, the title and the crc32 of the rom
+// so different roms (or patched roms) with the same title don't share a save
+func (c *Cartridge) SaveName() string {
+	return fmt.Sprintf("%s-%08X.save", strings.TrimSuffix(c.SaveTitle(), ".save"), crc32.ChecksumIEEE(c.ROM))
 }
 
 // SaveTitle get the save title for the game with null terminator removed etc
@@ -84,57 +124,100 @@ func (c *Cartridge) SaveTitle() string {
 	return title + ".save"
 }
 
+// IsNintendo whether the licensee code is Nintendo's
+// The CGB boot rom only colourises DMG games made by Nintendo
+func (c *Cartridge) IsNintendo() bool {
+	if c.OldLicenseeCode == 0x33 {
+		return c.NewLicenseeCode == "01"
+	}
+	return c.OldLicenseeCode == 0x01
+}
+
+// TitleChecksum the sum of the title bytes (0x0134 - 0x0143) used by the CGB
+// boot rom to choose a palette for DMG games
+func (c *Cartridge) TitleChecksum() byte {
+	var sum byte
+	for _, b := range c.ROM[0x0134:0x0144] {
+		sum += b
+	}
+	return sum
+}
+
 // String string representation of cart info
 func (c *Cartridge) String() string {
 	return fmt.Sprintf("Rom name: %s\nRam Size: %v | ROM Size: %v\nMBC Type: %s", c.Title, c.RAMSize, c.ROMSize, c.MBCType)
 }
 
 // LoadROM load and initialize a ROM based on cart path
-func LoadROM(rom []byte) (*Cartridge, error) {
+func LoadROM(rom []byte, opts LoadOptions) (*Cartridge, error) {
 	newCart := new(Cartridge)
-	newCart.ROM = rom // TODO - check this?
-	
-	newCart.InitCart(rom)
-	
+	newCart.strict = opts.Strict
+	if err := newCart.InitCart(rom); err != nil {
+		return nil, err
+	}
+
 	return newCart, nil
 }
 
 // InitCart initialize the cart
 func (c *Cartridge) InitCart(rom []byte) error {
+	if len(rom) < headerEnd {
+		return &TruncatedError{Size: len(rom), Want: headerEnd}
+	}
+	c.ROM = rom
 
 	c.HasCGBSupport = rom[0x0143] == 0x80 || rom[0x0143] == 0xC0
+	c.HasSGBSupport = rom[0x0146] == 0x03 && rom[0x014B] == 0x33
 	c.Title = string(rom[0x134:0x0143]) //TODO - make this actually good
-	romSize := rom[0x0148]
-	c.ROMSize = 0x8000 << romSize // TODO maybe error handle here?
-
-	switch rom[0x0149] {
-	case 0x00:
-		c.RAMSize = 0
-	case 0x01:
-		c.RAMSize = 0
-	case 0x02:
-		c.RAMSize = 8192
-	case 0x03:
-		c.RAMSize = 32678
-	case 0x04:
-		c.RAMSize = 131072
+
+	romSizeCode := rom[0x0148]
+	if romSizeCode <= 0x08 {
+		c.ROMSize = 0x8000 << romSizeCode
+	} else {
+		if err := c.problem(&SizeCodeError{Field: "ROM", Code: romSizeCode}); err != nil {
+			return err
+		}
+		c.ROMSize = 0x8000
+		for c.ROMSize < len(rom) { // guess from the size of the rom instead
+			c.ROMSize <<= 1
+		}
 	}
 
+	if size, found := ramSizes[rom[0x0149]]; found {
+		c.RAMSize = size
+	} else if err := c.problem(&SizeCodeError{Field: "RAM", Code: rom[0x0149]}); err != nil {
+		return err
+	}
+
+	if err := c.checkChecksums(); err != nil {
+		return err
+	}
+	if err := c.checkSize(); err != nil {
+		return err
+	}
+	rom = c.ROM
+
 	cType, found := CartTypes[rom[0x0147]]
 	if !found {
-		return fmt.Errorf("Unkown cart type found at %v", rom[0x0147])
+		return &UnknownCartTypeError{ID: rom[0x0147]}
 	}
 	c.Type = cType
 
 	c.IsJapanese = rom[0x014A] == 0x00
 
 	c.OldLicenseeCode = rom[0x014B]
-	// Check for new licensee code later
+	c.NewLicenseeCode = string(rom[0x0144:0x0146])
 
 	switch c.Type.ID {
 	case MBC_0:
-		c.MBC = NewMBC0(rom)
+		c.MBC = NewMBC0(rom, false, 0)
 		c.MBCType = "MBC0"
+	case ROM_RAM:
+		c.MBC = NewMBC0(rom, false, c.RAMSize)
+		c.MBCType = "MBC0 (ram)"
+	case ROM_RAM_BATTERY:
+		c.MBC = NewMBC0(rom, true, c.RAMSize)
+		c.MBCType = "MBC0 (ram and battery)"
 	case MBC_1, MBC_1_RAM:
 		c.MBC = NewMBC1(rom, c.ROMSize, c.RAMSize, false)
 		c.MBCType = "MBC1"
@@ -147,7 +230,7 @@ func (c *Cartridge) InitCart(rom []byte) error {
 	case MBC_2_BATTERY:
 		c.MBC = NewMBC2(rom, c.ROMSize, true)
 		c.MBCType = "MBC2 (Battery)"
-	case MBC_3:
+	case MBC_3, MBC_3_RAM:
 		c.MBC = NewMBC3(rom, false, true, c.RAMSize, c.ROMSize)
 		c.MBCType = "MBC3 (no battery) "
 	case MBC_3_RAM_BATTERY:
@@ -156,14 +239,100 @@ func (c *Cartridge) InitCart(rom []byte) error {
 	case MBC_3_TIMER_BATTERY, MBC_3_TIMER_RAM_BATTERY:
 		c.MBC = NewMBC3(rom, true, true, c.RAMSize, c.ROMSize)
 		c.MBCType = "MBC3 (battery and timer)"
-	case MBC_5, MBC_5_RAM, MBC_5_RUMBLE_RAM:
-		c.MBC = NewMBC5(rom, false, c.RAMSize, c.ROMSize)
+	case MBC_5, MBC_5_RAM:
+		c.MBC = NewMBC5(rom, false, false, c.RAMSize, c.ROMSize)
 		c.MBCType = "MBC5 (no battery)"
-	case MBC_5_RAM_BATTERY, MBC_5_RUMBLE_RAM_BATTERY:
-		c.MBC = NewMBC5(rom, true, c.RAMSize, c.ROMSize)
+	case MBC_5_RAM_BATTERY:
+		c.MBC = NewMBC5(rom, true, false, c.RAMSize, c.ROMSize)
 		c.MBCType = "MBC5 (battery)"
+	case MBC_5_RUMBLE, MBC_5_RUMBLE_RAM:
+		c.MBC = NewMBC5(rom, false, true, c.RAMSize, c.ROMSize)
+		c.MBCType = "MBC5 (rumble)"
+	case MBC_5_RUMBLE_RAM_BATTERY:
+		c.MBC = NewMBC5(rom, true, true, c.RAMSize, c.ROMSize)
+		c.MBCType = "MBC5 (battery and rumble)"
+	case MBC_6:
+		c.MBC = NewMBC6(rom, true, c.ROMSize)
+		c.MBCType = "MBC6"
+	case MBC_7_SENSOR_RUMBLE_RAM_BATTERY:
+		c.MBC = NewMBC7(rom, true, c.ROMSize)
+		c.MBCType = "MBC7"
+	case MMM01, MMM01_RAM:
+		c.MBC = NewMMM01(rom, false, c.RAMSize, c.ROMSize)
+		c.MBCType = "MMM01"
+	case MMM01_RAM_BATTERY:
+		c.MBC = NewMMM01(rom, true, c.RAMSize, c.ROMSize)
+		c.MBCType = "MMM01 (battery)"
+	case HUC1_RAM_BATTERY:
+		c.MBC = NewHuC1(rom, true, c.RAMSize, c.ROMSize)
+		c.MBCType = "HuC1"
+	case HUC3:
+		c.MBC = NewHuC3(rom, true, c.RAMSize, c.ROMSize)
+		c.MBCType = "HuC3"
+	case POCKET_CAMERA:
+		c.MBC = NewPocketCamera(rom, true, c.ROMSize)
+		c.MBCType = "Pocket Camera"
+	case BANDAI_TAMA5:
+		c.MBC = NewTAMA5(rom, c.ROMSize)
+		c.MBCType = "TAMA5"
 	default:
-		log.Fatalf("Not implemented cart type: %v, %v, %v", c.Type.ID, c.Type.Desc, c.Title)
+		return &UnsupportedMBCError{Type: c.Type}
+	}
+
+	if mbc1, ok := c.MBC.(*MBC1); ok && mbc1.IsMulticart() {
+		c.MBCType += " (multicart)"
+	}
+
+	return nil
+}
+
+// problem handle a problem with the rom that can be worked around, when loading
+// strictly it's returned as an error, otherwise it's added to the warnings
+func (c *Cartridge) problem(err error) error {
+	if c.strict {
+		return err
+	}
+
+	c.Warnings = append(c.Warnings, err)
+	return nil
+}
+
+// checkChecksums check the header checksum (0x014D) and the global checksum (0x014E - 0x014F)
+// The boot rom refuses to run with a bad header checksum but nothing checks the global one
+func (c *Cartridge) checkChecksums() error {
+	if sum := headerChecksum(c.ROM); sum != c.ROM[0x014D] {
+		err := &ChecksumError{Kind: "header", Want: uint16(c.ROM[0x014D]), Got: uint16(sum)}
+		if err := c.problem(err); err != nil {
+			return err
+		}
+	}
+
+	if sum, want := globalChecksum(c.ROM), storedGlobalChecksum(c.ROM); sum != want {
+		if err := c.problem(&ChecksumError{Kind: "global", Want: want, Got: sum}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// checkSize check the rom is the size the header says, short roms are padded
+// out with 0xFF so the banks can still be read
+func (c *Cartridge) checkSize() error {
+	switch {
+	case len(c.ROM) < c.ROMSize:
+		if err := c.problem(&TruncatedError{Size: len(c.ROM), Want: c.ROMSize}); err != nil {
+			return err
+		}
+		padded := make([]byte, c.ROMSize)
+		copy(padded, c.ROM)
+		for i := len(c.ROM); i < len(padded); i++ {
+			padded[i] = 0xFF
+		}
+		c.ROM = padded
+
+	case len(c.ROM) > c.ROMSize:
+		return c.problem(&SizeMismatchError{Size: len(c.ROM), Header: c.ROMSize})
 	}
 
 	return nil
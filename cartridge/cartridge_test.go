@@ -0,0 +1,132 @@
+package cartridge
+
+import (
+	"errors"
+	"testing"
+)
+
+// testROM build a 32KiB MBC0 rom with valid checksums, edit is run before the checksums are set
+func testROM(edit func(rom []byte)) []byte {
+	rom := make([]byte, 0x8000)
+	copy(rom[0x0134:], "TEST")
+	if edit != nil {
+		edit(rom)
+	}
+
+	var headerSum byte
+	for _, b := range rom[0x0134:0x014D] {
+		headerSum = headerSum - b - 1
+	}
+	rom[0x014D] = headerSum
+
+	var globalSum uint16
+	for i, b := range rom {
+		if i != 0x014E && i != 0x014F {
+			globalSum += uint16(b)
+		}
+	}
+	rom[0x014E], rom[0x014F] = byte(globalSum>>8), byte(globalSum)
+	return rom
+}
+
+func TestLoadROMErrors(t *testing.T) {
+	badHeader := testROM(nil)
+	badHeader[0x014D]++
+
+	tests := []struct {
+		name    string
+		rom     []byte
+		strict  bool
+		wantErr any // a pointer to the error type expected, nil for no error
+		warns   int
+	}{
+		{"valid", testROM(nil), true, nil, 0},
+		{"header only", make([]byte, 0x0100), false, new(*TruncatedError), 0},
+		{"unknown type", testROM(func(rom []byte) { rom[0x0147] = 0xEE }), false, new(*UnknownCartTypeError), 0},
+		{"bad rom size code", testROM(func(rom []byte) { rom[0x0148] = 0x40 }), true, new(*SizeCodeError), 0},
+		{"bad checksum", badHeader, true, new(*ChecksumError), 0},
+		{"bad checksum warns", badHeader, false, nil, 2}, // the header checksum is part of the global one
+		{"truncated", testROM(func(rom []byte) { rom[0x0148] = 0x01 }), true, new(*TruncatedError), 0},
+		{"truncated is padded", testROM(func(rom []byte) { rom[0x0148] = 0x01 }), false, nil, 1},
+		{"too big", append(testROM(nil), 0), true, new(*SizeMismatchError), 0},
+		{"rom+ram", testROM(func(rom []byte) { rom[0x0147], rom[0x0149] = ROM_RAM_BATTERY, 0x02 }), true, nil, 0},
+		{"mbc3+ram", testROM(func(rom []byte) { rom[0x0147], rom[0x0149] = MBC_3_RAM, 0x03 }), true, nil, 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cart, err := LoadROM(test.rom, LoadOptions{Strict: test.strict})
+
+			if test.wantErr == nil {
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				if len(cart.Warnings) != test.warns {
+					t.Fatalf("Got warnings %v, want %d", cart.Warnings, test.warns)
+				}
+				if len(cart.ROM) < cart.ROMSize {
+					t.Fatalf("ROM is %d bytes, shorter than its size %d", len(cart.ROM), cart.ROMSize)
+				}
+				return
+			}
+
+			if !errors.As(err, test.wantErr) {
+				t.Fatalf("Got error %v, want %T", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseHeader(t *testing.T) {
+	rom := testROM(func(rom []byte) {
+		copy(rom[0x0104:], nintendoLogo)
+		copy(rom[0x0134:], "POKEMON_GLDAAUE")
+		rom[0x0143] = 0x80
+		rom[0x0144], rom[0x0145] = '0', '1'
+		rom[0x0146] = 0x03
+		rom[0x0147] = MBC_3_TIMER_RAM_BATTERY
+		rom[0x0149] = 0x03
+		rom[0x014A] = 0x01
+		rom[0x014B] = 0x33
+	})
+
+	h, err := ParseHeader(rom)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if h.Title != "POKEMON_GLD" || h.ManufacturerCode != "AAUE" {
+		t.Errorf("Got title %q and manufacturer %q", h.Title, h.ManufacturerCode)
+	}
+	if h.CGB != "CGB enhanced" || !h.SGB || h.Licensee != "Nintendo Research & Development 1" {
+		t.Errorf("Got CGB %q, SGB %v, licensee %q", h.CGB, h.SGB, h.Licensee)
+	}
+	if h.RAMSize != 0x8000 || h.ROMSize != 0x8000 || h.Destination != "Overseas" {
+		t.Errorf("Got RAM %d, ROM %d, destination %q", h.RAMSize, h.ROMSize, h.Destination)
+	}
+	if !h.HeaderChecksumOK || !h.GlobalChecksumOK || !h.LogoOK {
+		t.Errorf("Got header checksum %v, global %v, logo %v", h.HeaderChecksumOK, h.GlobalChecksumOK, h.LogoOK)
+	}
+
+	if _, err := ParseHeader(rom[:0x0140]); err == nil {
+		t.Errorf("Expected an error for a truncated header")
+	}
+}
+
+func TestRAMSizeMatchesHeader(t *testing.T) {
+	for code := range ramSizes {
+		rom := testROM(func(rom []byte) { rom[0x0147], rom[0x0149] = MBC_3_RAM, code })
+
+		cart, err := LoadROM(rom, LoadOptions{Strict: true})
+		if err != nil {
+			t.Fatalf("code 0x%02X: unexpected error: %v", code, err)
+		}
+		h, err := ParseHeader(rom)
+		if err != nil {
+			t.Fatalf("code 0x%02X: unexpected error: %v", code, err)
+		}
+		if cart.RAMSize != h.RAMSize {
+			t.Errorf("code 0x%02X: cart has %d bytes of RAM, header says %d", code, cart.RAMSize, h.RAMSize)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package cartridge
+
+import "fmt"
+
+// TruncatedError the rom is shorter than its header or the size the header gives
+type TruncatedError struct {
+	Size int // the size of the rom
+	Want int // the size it should be
+}
+
+func (e *TruncatedError) Error() string {
+	return fmt.Sprintf("ROM is truncated, it's %d bytes but should be %d", e.Size, e.Want)
+}
+
+// UnknownCartTypeError the cart type at 0x0147 isn't a known type
+type UnknownCartTypeError struct {
+	ID byte
+}
+
+func (e *UnknownCartTypeError) Error() string {
+	return fmt.Sprintf("Unknown cart type 0x%02X", e.ID)
+}
+
+// UnsupportedMBCError the cart type is known but its MBC isn't emulated
+type UnsupportedMBCError struct {
+	Type CartType
+}
+
+func (e *UnsupportedMBCError) Error() string {
+	return fmt.Sprintf("Unsupported cart type 0x%02X (%s)", e.Type.ID, e.Type.Desc)
+}
+
+// SizeCodeError the ROM or RAM size code in the header isn't valid
+type SizeCodeError struct {
+	Field string // "ROM" or "RAM"
+	Code  byte
+}
+
+func (e *SizeCodeError) Error() string {
+	return fmt.Sprintf("Invalid %s size code 0x%02X", e.Field, e.Code)
+}
+
+// SizeMismatchError the rom is bigger than the size the header gives
+type SizeMismatchError struct {
+	Size   int // the size of the rom
+	Header int // the size from the header
+}
+
+func (e *SizeMismatchError) Error() string {
+	return fmt.Sprintf("ROM is %d bytes but the header says %d", e.Size, e.Header)
+}
+
+// ChecksumError the header or global checksum doesn't match the rom
+type ChecksumError struct {
+	Kind string // "header" or "global"
+	Want uint16 // the checksum stored in the header
+	Got  uint16 // the checksum of the rom
+}
+
+func (e *ChecksumError) Error() string {
+	return fmt.Sprintf("Bad %s checksum, header has 0x%02X but the ROM sums to 0x%02X", e.Kind, e.Want, e.Got)
+}
@@ -0,0 +1,220 @@
+package cartridge
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// nintendoLogo the logo at 0x0104 - 0x0133, the boot rom locks up if it doesn't match
+var nintendoLogo = []byte{
+	0xCE, 0xED, 0x66, 0x66, 0xCC, 0x0D, 0x00, 0x0B, 0x03, 0x73, 0x00, 0x83, 0x00, 0x0C, 0x00, 0x0D,
+	0x00, 0x08, 0x11, 0x1F, 0x88, 0x89, 0x00, 0x0E, 0xDC, 0xCC, 0x6E, 0xE6, 0xDD, 0xDD, 0xD9, 0x99,
+	0xBB, 0xBB, 0x67, 0x63, 0x6E, 0x0E, 0xEC, 0xCC, 0xDD, 0xDC, 0x99, 0x9F, 0xBB, 0xB9, 0x33, 0x3E,
+}
+
+// Header everything in the cartridge header (0x0100 - 0x014F) decoded
+type Header struct {
+	Title            string `json:"title"`
+	ManufacturerCode string `json:"manufacturer_code"` // only on some later carts, empty if there isn't one
+	CGBFlag          byte   `json:"cgb_flag"`
+	CGB              string `json:"cgb"` // "DMG only", "CGB enhanced" or "CGB only"
+	SGBFlag          byte   `json:"sgb_flag"`
+	SGB              bool   `json:"sgb"` // whether the SGB functions are enabled (also needs the old licensee code to be 0x33)
+	OldLicenseeCode  byte   `json:"old_licensee_code"`
+	NewLicenseeCode  string `json:"new_licensee_code,omitempty"` // only set if the old code is 0x33
+	Licensee         string `json:"licensee"`                    // the name for the licensee code in use, "Unknown" if it isn't known
+	CartTypeCode     byte   `json:"cart_type_code"`
+	CartType         string `json:"cart_type"`
+	ROMSizeCode      byte   `json:"rom_size_code"`
+	ROMSize          int    `json:"rom_size"` // in bytes, 0 if the code is invalid
+	RAMSizeCode      byte   `json:"ram_size_code"`
+	RAMSize          int    `json:"ram_size"` // in bytes, -1 if the code is invalid
+	DestinationCode  byte   `json:"destination_code"`
+	Destination      string `json:"destination"` // "Japan" or "Overseas"
+	Version          byte   `json:"version"`
+	HeaderChecksum   byte   `json:"header_checksum"`
+	HeaderChecksumOK bool   `json:"header_checksum_ok"`
+	GlobalChecksum   uint16 `json:"global_checksum"`
+	GlobalChecksumOK bool   `json:"global_checksum_ok"`
+	LogoOK           bool   `json:"logo_ok"`
+	FileSize         int    `json:"file_size"`
+}
+
+// ParseHeader decode the header of rom, this works for carts that can't be
+// emulated too and only fails if the rom is too short to have a header
+func ParseHeader(rom []byte) (*Header, error) {
+	if len(rom) < headerEnd {
+		return nil, &TruncatedError{Size: len(rom), Want: headerEnd}
+	}
+
+	h := &Header{
+		CGBFlag:          rom[0x0143],
+		SGBFlag:          rom[0x0146],
+		SGB:              rom[0x0146] == 0x03 && rom[0x014B] == 0x33,
+		OldLicenseeCode:  rom[0x014B],
+		CartTypeCode:     rom[0x0147],
+		ROMSizeCode:      rom[0x0148],
+		RAMSizeCode:      rom[0x0149],
+		DestinationCode:  rom[0x014A],
+		Version:          rom[0x014C],
+		HeaderChecksum:   rom[0x014D],
+		HeaderChecksumOK: headerChecksum(rom) == rom[0x014D],
+		GlobalChecksum:   storedGlobalChecksum(rom),
+		GlobalChecksumOK: globalChecksum(rom) == storedGlobalChecksum(rom),
+		LogoOK:           bytes.Equal(rom[0x0104:0x0134], nintendoLogo),
+		FileSize:         len(rom),
+	}
+
+	h.Title, h.ManufacturerCode = parseTitle(rom)
+	if h.OldLicenseeCode == 0x33 {
+		h.NewLicenseeCode = string(rom[0x0144:0x0146])
+	}
+
+	switch h.CGBFlag {
+	case 0x80:
+		h.CGB = "CGB enhanced"
+	case 0xC0:
+		h.CGB = "CGB only"
+	default:
+		h.CGB = "DMG only"
+	}
+
+	h.Licensee = licenseeName(h.OldLicenseeCode, h.NewLicenseeCode)
+
+	h.CartType = "Unknown"
+	if cType, found := CartTypes[h.CartTypeCode]; found {
+		h.CartType = cType.Desc
+	}
+
+	if h.ROMSizeCode <= 0x08 {
+		h.ROMSize = 0x8000 << h.ROMSizeCode
+	}
+	h.RAMSize = -1
+	if size, found := ramSizes[h.RAMSizeCode]; found {
+		h.RAMSize = size
+	}
+
+	h.Destination = "Overseas"
+	if h.DestinationCode == 0x00 {
+		h.Destination = "Japan"
+	}
+
+	return h, nil
+}
+
+// ramSizes the RAM size in bytes for each RAM size code, used for both the header and
+// the cart (0x01 was never used by a released cart but is listed as 2KiB)
+var ramSizes = map[byte]int{0x00: 0, 0x01: 0x800, 0x02: 0x2000, 0x03: 0x8000, 0x04: 0x20000, 0x05: 0x10000}
+
+// parseTitle get the title and manufacturer code, the title takes up 0x0134 - 0x0143
+// on old carts but CGB carts use 0x0143 for the CGB flag and some put a
+// 4 character manufacturer code in 0x013F - 0x0142
+func parseTitle(rom []byte) (title, manufacturer string) {
+	end := 0x0144
+	if rom[0x0143]&0x80 != 0 {
+		end = 0x0143
+		if code := rom[0x013F:0x0143]; rom[0x013E] != 0 && isManufacturerCode(code) {
+			manufacturer = string(code)
+			end = 0x013F
+		}
+	}
+
+	return string(bytes.TrimRight(rom[0x0134:end], "\x00 ")), manufacturer
+}
+
+// isManufacturerCode whether code looks like a manufacturer code (4 uppercase letters or digits)
+// NOTE - a title that fills up the whole space can look like one too, there's no way to tell for sure
+func isManufacturerCode(code []byte) bool {
+	for _, c := range code {
+		if (c < 'A' || c > 'Z') && (c < '0' || c > '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// headerChecksum calculate the checksum of 0x0134 - 0x014C like the boot rom does
+func headerChecksum(rom []byte) byte {
+	var sum byte
+	for _, b := range rom[0x0134:0x014D] {
+		sum = sum - b - 1
+	}
+	return sum
+}
+
+// globalChecksum the sum of every byte in the rom except the global checksum itself
+func globalChecksum(rom []byte) uint16 {
+	var sum uint16
+	for i, b := range rom {
+		if i != 0x014E && i != 0x014F {
+			sum += uint16(b)
+		}
+	}
+	return sum
+}
+
+// storedGlobalChecksum the global checksum stored in the header (big endian)
+func storedGlobalChecksum(rom []byte) uint16 {
+	return uint16(rom[0x014E])<<8 | uint16(rom[0x014F])
+}
+
+// licenseeName get the name of the publisher, the new code is used if the old one is 0x33
+func licenseeName(oldCode byte, newCode string) string {
+	if oldCode == 0x33 {
+		if name, found := newLicensees[newCode]; found {
+			return name
+		}
+		return "Unknown"
+	}
+
+	if name, found := oldLicensees[oldCode]; found {
+		return name
+	}
+	return "Unknown"
+}
+
+// String describe the header with one field on each line
+func (h *Header) String() string {
+	okStr := func(ok bool) string {
+		if ok {
+			return "ok"
+		}
+		return "BAD"
+	}
+
+	licensee := fmt.Sprintf("%s (old code 0x%02X)", h.Licensee, h.OldLicenseeCode)
+	if h.OldLicenseeCode == 0x33 {
+		licensee = fmt.Sprintf("%s (new code %q)", h.Licensee, h.NewLicenseeCode)
+	}
+
+	ramSize := "invalid"
+	if h.RAMSize >= 0 {
+		ramSize = fmt.Sprintf("%d KiB", h.RAMSize/1024)
+	}
+	romSize := "invalid"
+	if h.ROMSize > 0 {
+		romSize = fmt.Sprintf("%d KiB", h.ROMSize/1024)
+	}
+
+	lines := []string{
+		fmt.Sprintf("Title:           %s", h.Title),
+		fmt.Sprintf("Manufacturer:    %s", h.ManufacturerCode),
+		fmt.Sprintf("CGB:             %s (0x%02X)", h.CGB, h.CGBFlag),
+		fmt.Sprintf("SGB:             %v (0x%02X)", h.SGB, h.SGBFlag),
+		fmt.Sprintf("Licensee:        %s", licensee),
+		fmt.Sprintf("Cart type:       %s (0x%02X)", h.CartType, h.CartTypeCode),
+		fmt.Sprintf("ROM size:        %s (0x%02X), file is %d bytes", romSize, h.ROMSizeCode, h.FileSize),
+		fmt.Sprintf("RAM size:        %s (0x%02X)", ramSize, h.RAMSizeCode),
+		fmt.Sprintf("Destination:     %s (0x%02X)", h.Destination, h.DestinationCode),
+		fmt.Sprintf("Version:         %d", h.Version),
+		fmt.Sprintf("Header checksum: 0x%02X %s", h.HeaderChecksum, okStr(h.HeaderChecksumOK)),
+		fmt.Sprintf("Global checksum: 0x%04X %s", h.GlobalChecksum, okStr(h.GlobalChecksumOK)),
+		fmt.Sprintf("Nintendo logo:   %s", okStr(h.LogoOK)),
+	}
+
+	var buf bytes.Buffer
+	for _, line := range lines {
+		buf.WriteString(line + "\n")
+	}
+	return buf.String()
+}
@@ -0,0 +1,118 @@
+package cartridge
+
+import "io"
+
+///////////////
+// HuC1 Cart //
+///////////////
+//
+// Like a MBC1 without the banking modes but with an infrared LED and sensor that
+// can be mapped to 0xA000-0xBFFF instead of the ram. The IR port is stubbed, the
+// sensor never sees any light.
+
+type HuC1 struct {
+	rom        banks // every rom bank including bank 0
+	romBank    byte  // the active rom bank (6 bits, never 0)
+	ram        banks // the ram banks
+	ramBank    byte  // the active ram bank (2 bits)
+	hasRAM     bool  // whether or not the cart has ram
+	irMapped   bool  // if true the IR port is mapped to 0xA000-0xBFFF instead of the ram
+	irLED      bool  // whether the game has the IR LED on
+	hasBattery bool
+}
+
+// LoadFile implements MemoryBankController.
+func (m *HuC1) LoadFile(file io.Reader) error {
+	if !m.hasBattery || !m.hasRAM {
+		return nil
+	}
+	return m.ram.load(file)
+}
+
+// SaveFile implements MemoryBankController.
+func (m *HuC1) SaveFile(file io.Writer) error {
+	if !m.hasBattery || !m.hasRAM {
+		return nil
+	}
+
+	return m.ram.save(file)
+}
+
+// NewHuC1 create and return a new HuC1
+func NewHuC1(rom []byte, hasBattery bool, ramSize, romSize int) *HuC1 {
+	mbc := new(HuC1)
+	mbc.hasBattery = hasBattery
+
+	if ramSize > 0 {
+		mbc.hasRAM = true
+		mbc.ram = newRAMBanks(ramSize, 0x2000)
+	}
+
+	mbc.rom = newROMBanks(rom, romSize, 0x4000)
+	mbc.romBank = 1
+	return mbc
+}
+
+// ReadByte read byte from addr
+func (m *HuC1) ReadByte(addr uint16) byte {
+	switch {
+	case addr <= 0x3FFF:
+		return m.rom.read(0, addr)
+
+	case addr >= 0x4000 && addr <= 0x7FFF:
+		return m.rom.read(int(m.romBank), addr-0x4000)
+
+	case addr >= 0xA000 && addr <= 0xBFFF:
+		if m.irMapped {
+			return 0xC0 // bit 0 clear - no light seen
+		}
+		if m.hasRAM {
+			return m.ram.read(int(m.ramBank), addr-0xA000)
+		}
+	}
+
+	return 0xFF
+}
+
+// WriteByte write data to addr
+// Returns whether the save changed
+func (m *HuC1) WriteByte(addr uint16, data byte) bool {
+	switch {
+	case addr <= 0x1FFF: // 0x0E maps the IR port, anything else maps the ram
+		m.irMapped = data == 0x0E
+
+	case addr >= 0x2000 && addr <= 0x3FFF:
+		m.switchROMBank(int(data))
+
+	case addr >= 0x4000 && addr <= 0x5FFF:
+		m.switchRAMBank(int(data))
+
+	case addr >= 0xA000 && addr <= 0xBFFF:
+		if m.irMapped {
+			m.irLED = data&0x01 != 0
+			return false
+		}
+		if m.hasRAM {
+			return m.ram.write(int(m.ramBank), addr-0xA000, data)
+		}
+	}
+	return false
+}
+
+// switchROMBank switch to the rom bank in the lower 6 bits of bank, 0 is treated as 1
+func (m *HuC1) switchROMBank(bank int) {
+	m.romBank = byte(bank & 0x3F)
+	if m.romBank == 0 {
+		m.romBank = 1
+	}
+}
+
+// switchRAMBank switch to the ram bank in the lower 2 bits of bank
+func (m *HuC1) switchRAMBank(bank int) {
+	m.ramBank = byte(bank & 0x03)
+}
+
+// HasBattery return whether or not MBC supports battery
+func (m *HuC1) HasBattery() bool {
+	return m.hasBattery
+}
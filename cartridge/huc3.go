@@ -0,0 +1,267 @@
+package cartridge
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"time"
+)
+
+///////////////
+// HuC3 Cart //
+///////////////
+//
+// Like the HuC1 but with a real time clock. What's mapped to 0xA000-0xBFFF is
+// picked with 0x0000-0x1FFF, the clock is talked to by writing commands in
+// mode 0xB, reading the result in mode 0xC and waiting on the semaphore in mode 0xD.
+//
+// The clock keeps the minute of the day and the day in 12 bits each, the
+// games copy them to and from nibbles 0x00-0x05 of the clocks memory.
+
+// the HuC3 modes picked by writing to 0x0000-0x1FFF
+const (
+	huc3RAMRead   = 0x0
+	huc3RAM       = 0xA
+	huc3RTCWrite  = 0xB
+	huc3RTCRead   = 0xC
+	huc3Semaphore = 0xD
+	huc3IR        = 0xE
+)
+
+// the HuC3 RTC commands (bits 4-6 of a write in mode 0xB)
+const (
+	huc3ReadNext   = 0x1 // read the nibble at the index and move to the next one
+	huc3WriteNext  = 0x3 // write the argument at the index and move to the next one
+	huc3IndexLow   = 0x4 // set the lower nibble of the index
+	huc3IndexHigh  = 0x5 // set the upper nibble of the index
+	huc3Extended   = 0x6 // the argument picks a command from below
+	huc3LatchTime  = 0x0 // copy the clock into nibbles 0x00-0x05
+	huc3SetTime    = 0x1 // copy nibbles 0x00-0x05 into the clock
+	huc3Status     = 0x2 // check the clock is there, always reads 1
+	minutesPerDay  = 24 * 60
+	huc3DayMask    = 0xFFF
+	huc3RTCSaveLen = 12 // the bytes saved for the clock after the ram
+)
+
+type HuC3 struct {
+	rom        banks // every rom bank including bank 0
+	romBank    byte  // the active rom bank (7 bits, never 0)
+	ram        banks // the ram banks
+	ramBank    byte  // the active ram bank (2 bits)
+	hasRAM     bool
+	hasBattery bool
+	mode       byte // what's mapped to 0xA000-0xBFFF, one of the huc3 modes
+
+	rtc huc3RTC
+}
+
+// huc3RTC the HuC3 clock and the memory games talk to it with
+type huc3RTC struct {
+	minutes    int       // the minute of the day
+	days       int       // the days since the clock was set (12 bits)
+	lastUpdate time.Time // when the clock was last brought up to date
+	memory     [256]byte // the clocks memory, each byte holds a nibble
+	index      byte      // the memory address used by the read and write commands
+	command    byte      // the last command, read back in mode 0xC
+	result     byte      // the result of the last command
+}
+
+// LoadFile implements MemoryBankController.
+// The ram is followed by the clock, old saves without it start the clock from now
+func (m *HuC3) LoadFile(file io.Reader) error {
+	if !m.hasBattery {
+		return nil
+	}
+
+	if m.hasRAM {
+		if err := m.ram.load(file); err != nil {
+			return err
+		}
+	}
+
+	return m.rtc.load(file)
+}
+
+// SaveFile implements MemoryBankController.
+func (m *HuC3) SaveFile(file io.Writer) error {
+	if !m.hasBattery {
+		return nil
+	}
+
+	if m.hasRAM {
+		if err := m.ram.save(file); err != nil {
+			return err
+		}
+	}
+
+	return m.rtc.save(file)
+}
+
+// NewHuC3 create and return a new HuC3
+func NewHuC3(rom []byte, hasBattery bool, ramSize, romSize int) *HuC3 {
+	mbc := new(HuC3)
+	mbc.hasBattery = hasBattery
+
+	if ramSize > 0 {
+		mbc.hasRAM = true
+		mbc.ram = newRAMBanks(ramSize, 0x2000)
+	}
+
+	mbc.rom = newROMBanks(rom, romSize, 0x4000)
+	mbc.romBank = 1
+	mbc.rtc.lastUpdate = time.Now()
+	return mbc
+}
+
+// ReadByte read byte from addr
+func (m *HuC3) ReadByte(addr uint16) byte {
+	switch {
+	case addr <= 0x3FFF:
+		return m.rom.read(0, addr)
+
+	case addr >= 0x4000 && addr <= 0x7FFF:
+		return m.rom.read(int(m.romBank), addr-0x4000)
+
+	case addr >= 0xA000 && addr <= 0xBFFF:
+		switch m.mode {
+		case huc3RAMRead, huc3RAM:
+			if m.hasRAM {
+				return m.ram.read(int(m.ramBank), addr-0xA000)
+			}
+		case huc3RTCRead:
+			return 0x80 | m.rtc.command<<4 | m.rtc.result
+		case huc3Semaphore:
+			return 0xFF // commands finish straight away so the clock is always ready
+		case huc3IR:
+			return 0xC0 // no light seen
+		}
+	}
+
+	return 0xFF
+}
+
+// WriteByte write data to addr
+// Returns whether the save changed
+func (m *HuC3) WriteByte(addr uint16, data byte) bool {
+	switch {
+	case addr <= 0x1FFF:
+		m.mode = data & 0x0F
+
+	case addr >= 0x2000 && addr <= 0x3FFF:
+		m.switchROMBank(int(data))
+
+	case addr >= 0x4000 && addr <= 0x5FFF:
+		m.switchRAMBank(int(data))
+
+	case addr >= 0xA000 && addr <= 0xBFFF:
+		switch m.mode {
+		case huc3RAM:
+			if m.hasRAM {
+				return m.ram.write(int(m.ramBank), addr-0xA000, data)
+			}
+		case huc3RTCWrite:
+			return m.rtc.runCommand((data>>4)&0x07, data&0x0F)
+		}
+	}
+	return false
+}
+
+// switchROMBank switch to the rom bank in the lower 7 bits of bank, 0 is treated as 1
+func (m *HuC3) switchROMBank(bank int) {
+	m.romBank = byte(bank & 0x7F)
+	if m.romBank == 0 {
+		m.romBank = 1
+	}
+}
+
+// switchRAMBank switch to the ram bank in the lower 2 bits of bank
+func (m *HuC3) switchRAMBank(bank int) {
+	m.ramBank = byte(bank & 0x03)
+}
+
+// HasBattery return whether or not MBC supports battery
+func (m *HuC3) HasBattery() bool {
+	return m.hasBattery
+}
+
+// update bring the clock up to date with the real time
+func (r *huc3RTC) update() {
+	elapsed := int(time.Since(r.lastUpdate) / time.Minute)
+	if elapsed <= 0 {
+		return
+	}
+	r.lastUpdate = r.lastUpdate.Add(time.Duration(elapsed) * time.Minute)
+
+	total := r.minutes + elapsed
+	r.minutes = total % minutesPerDay
+	r.days = (r.days + total/minutesPerDay) & huc3DayMask
+}
+
+// runCommand run a clock command with the given argument
+// Returns whether it set the clock, which is part of the save
+func (r *huc3RTC) runCommand(command, arg byte) bool {
+	r.command = command
+
+	switch command {
+	case huc3ReadNext:
+		r.result = r.memory[r.index] & 0x0F
+		r.index++
+	case huc3WriteNext:
+		r.memory[r.index] = arg
+		r.index++
+	case huc3IndexLow:
+		r.index = r.index&0xF0 | arg
+	case huc3IndexHigh:
+		r.index = r.index&0x0F | arg<<4
+	case huc3Extended:
+		switch arg {
+		case huc3LatchTime:
+			r.update()
+			for i := 0; i < 3; i++ {
+				r.memory[i] = byte(r.minutes>>(i*4)) & 0x0F
+				r.memory[i+3] = byte(r.days>>(i*4)) & 0x0F
+			}
+		case huc3SetTime:
+			r.minutes, r.days = 0, 0
+			for i := 0; i < 3; i++ {
+				r.minutes |= int(r.memory[i]&0x0F) << (i * 4)
+				r.days |= int(r.memory[i+3]&0x0F) << (i * 4)
+			}
+			r.minutes %= minutesPerDay
+			r.lastUpdate = time.Now()
+			return true
+		case huc3Status:
+			r.result = 0x01
+		}
+	}
+	return false
+}
+
+// save write the clock as the minutes and days (uint16s) then the unix time it was saved at (int64)
+func (r *huc3RTC) save(file io.Writer) error {
+	r.update()
+	data := []any{uint16(r.minutes), uint16(r.days), r.lastUpdate.Unix()}
+	for _, value := range data {
+		if err := binary.Write(file, binary.LittleEndian, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// load read the clock written by save and catch it up with the time since then
+func (r *huc3RTC) load(file io.Reader) error {
+	var data [huc3RTCSaveLen]byte
+	if _, err := io.ReadFull(file, data[:]); err != nil {
+		if errors.Is(err, io.EOF) { // no clock saved, leave it starting from now
+			return nil
+		}
+		return err
+	}
+
+	r.minutes = int(binary.LittleEndian.Uint16(data[0:])) % minutesPerDay
+	r.days = int(binary.LittleEndian.Uint16(data[2:])) & huc3DayMask
+	r.lastUpdate = time.Unix(int64(binary.LittleEndian.Uint64(data[4:])), 0)
+	r.update()
+	return nil
+}
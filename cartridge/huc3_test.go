@@ -0,0 +1,54 @@
+package cartridge
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+// huc3Command send a clock command and return the result nibble
+func huc3Command(mbc *HuC3, command, arg byte) byte {
+	mbc.WriteByte(0x0000, huc3RTCWrite)
+	mbc.WriteByte(0xA000, command<<4|arg)
+	mbc.WriteByte(0x0000, huc3RTCRead)
+	return mbc.ReadByte(0xA000) & 0x0F
+}
+
+func TestHuC3Clock(t *testing.T) {
+	mbc := NewHuC3(bankedROM(4), true, 0x2000, 4*0x4000)
+
+	// set the clock to day 0x123 minute 0x2AB through the clock memory
+	huc3Command(mbc, huc3IndexLow, 0)
+	huc3Command(mbc, huc3IndexHigh, 0)
+	for _, nibble := range []byte{0xB, 0xA, 0x2, 0x3, 0x2, 0x1} {
+		huc3Command(mbc, huc3WriteNext, nibble)
+	}
+	huc3Command(mbc, huc3Extended, huc3SetTime)
+
+	mbc.rtc.lastUpdate = mbc.rtc.lastUpdate.Add(-(time.Hour + 30*time.Second)) // an hour passes
+	huc3Command(mbc, huc3Extended, huc3LatchTime)
+
+	huc3Command(mbc, huc3IndexLow, 0)
+	var minutes, days int
+	for i := 0; i < 3; i++ {
+		minutes |= int(huc3Command(mbc, huc3ReadNext, 0)) << (i * 4)
+	}
+	for i := 0; i < 3; i++ {
+		days |= int(huc3Command(mbc, huc3ReadNext, 0)) << (i * 4)
+	}
+	if minutes != 0x2AB+60 || days != 0x123 {
+		t.Fatalf("Got minute 0x%03X day 0x%03X, want 0x%03X 0x123", minutes, days, 0x2AB+60)
+	}
+
+	var save bytes.Buffer
+	if err := mbc.SaveFile(&save); err != nil {
+		t.Fatalf("Failed to save: %v", err)
+	}
+	loaded := NewHuC3(bankedROM(4), true, 0x2000, 4*0x4000)
+	if err := loaded.LoadFile(&save); err != nil {
+		t.Fatalf("Failed to load: %v", err)
+	}
+	if loaded.rtc.minutes != mbc.rtc.minutes || loaded.rtc.days != mbc.rtc.days {
+		t.Errorf("Got %d:%d after loading, want %d:%d", loaded.rtc.days, loaded.rtc.minutes, mbc.rtc.days, mbc.rtc.minutes)
+	}
+}
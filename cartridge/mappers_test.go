@@ -0,0 +1,158 @@
+package cartridge
+
+import (
+	"bytes"
+	"testing"
+)
+
+// TestMapperSaves check each of the less common mappers loads back what it saved
+func TestMapperSaves(t *testing.T) {
+	tests := []struct {
+		name  string
+		new   func() MemoryBankController
+		setup func(mbc MemoryBankController) // write something that should be saved
+		check func(mbc MemoryBankController) byte
+		want  byte
+	}{
+		{
+			"MMM01",
+			func() MemoryBankController { return NewMMM01(bankedROM(8), true, 0x2000, 8*0x4000) },
+			func(mbc MemoryBankController) { mbc.WriteByte(0x0000, 0x4A); mbc.WriteByte(0xA010, 0x42) },
+			func(mbc MemoryBankController) byte { mbc.WriteByte(0x0000, 0x4A); return mbc.ReadByte(0xA010) },
+			0x42,
+		},
+		{
+			"HuC1",
+			func() MemoryBankController { return NewHuC1(bankedROM(8), true, 0x8000, 8*0x4000) },
+			func(mbc MemoryBankController) { mbc.WriteByte(0x4000, 0x02); mbc.WriteByte(0xA010, 0x42) },
+			func(mbc MemoryBankController) byte { mbc.WriteByte(0x4000, 0x02); return mbc.ReadByte(0xA010) },
+			0x42,
+		},
+		{
+			"MBC6 flash",
+			func() MemoryBankController { return NewMBC6(bankedROM(8), true, 8*0x4000) },
+			func(mbc MemoryBankController) {
+				for _, w := range [][2]uint16{{0x0C00, 1}, {0x1000, 1}, {0x2800, 8}, {0x3800, 8}} {
+					mbc.WriteByte(w[0], byte(w[1]))
+				}
+				flashWrite := func(bank byte, addr uint16, data byte) {
+					mbc.WriteByte(0x2000, bank)
+					mbc.WriteByte(0x4000|addr&0x1FFF, data)
+				}
+				flashWrite(2, 0x5555, 0xAA)
+				flashWrite(1, 0x2AAA, 0x55)
+				flashWrite(2, 0x5555, 0xA0)
+				flashWrite(7, 0x0010, 0x42)
+			},
+			func(mbc MemoryBankController) byte {
+				mbc.WriteByte(0x0C00, 1)
+				mbc.WriteByte(0x2800, 8)
+				mbc.WriteByte(0x2000, 7)
+				return mbc.ReadByte(0x4010)
+			},
+			0x42,
+		},
+		{
+			"Pocket Camera",
+			func() MemoryBankController { return NewPocketCamera(bankedROM(8), true, 8*0x4000) },
+			func(mbc MemoryBankController) {
+				mbc.WriteByte(0x0000, 0x0A)
+				mbc.WriteByte(0x4000, 0x0F)
+				mbc.WriteByte(0xA010, 0x42)
+			},
+			func(mbc MemoryBankController) byte { mbc.WriteByte(0x4000, 0x0F); return mbc.ReadByte(0xA010) },
+			0x42,
+		},
+		{
+			"TAMA5",
+			func() MemoryBankController { return NewTAMA5(bankedROM(8), 8*0x4000) },
+			func(mbc MemoryBankController) {
+				for _, reg := range [][2]byte{{tama5DataLow, 0x2}, {tama5DataHigh, 0x4}, {tama5Command, 0x1}, {tama5AddrLow, 0x3}} {
+					mbc.WriteByte(0xA001, reg[0])
+					mbc.WriteByte(0xA000, reg[1])
+				}
+			},
+			func(mbc MemoryBankController) byte {
+				for _, reg := range [][2]byte{{tama5Command, 0x3}, {tama5AddrLow, 0x3}} {
+					mbc.WriteByte(0xA001, reg[0])
+					mbc.WriteByte(0xA000, reg[1])
+				}
+				mbc.WriteByte(0xA001, tama5ResultLow)
+				low := mbc.ReadByte(0xA000) & 0x0F
+				mbc.WriteByte(0xA001, tama5ResultHi)
+				return mbc.ReadByte(0xA000)<<4 | low
+			},
+			0x42,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mbc := test.new()
+			test.setup(mbc)
+
+			var save bytes.Buffer
+			if err := mbc.SaveFile(&save); err != nil {
+				t.Fatalf("Failed to save: %v", err)
+			}
+
+			loaded := test.new()
+			if err := loaded.LoadFile(&save); err != nil {
+				t.Fatalf("Failed to load: %v", err)
+			}
+			if got := test.check(loaded); got != test.want {
+				t.Errorf("Got 0x%02X after loading, want 0x%02X", got, test.want)
+			}
+		})
+	}
+}
+
+func TestMBC6FlashChangesSave(t *testing.T) {
+	mbc := NewMBC6(bankedROM(8), true, 8*0x4000)
+	for _, w := range [][2]uint16{{0x0C00, 1}, {0x1000, 1}, {0x2800, 8}} {
+		mbc.WriteByte(w[0], byte(w[1]))
+	}
+
+	writes := []struct {
+		bank    byte
+		addr    uint16
+		data    byte
+		changed bool
+	}{
+		{2, 0x5555, 0xAA, false}, {1, 0x2AAA, 0x55, false}, {2, 0x5555, 0xA0, false},
+		{7, 0x0010, 0x42, true}, // program
+		{2, 0x5555, 0xAA, false}, {1, 0x2AAA, 0x55, false}, {2, 0x5555, 0x80, false},
+		{2, 0x5555, 0xAA, false}, {1, 0x2AAA, 0x55, false},
+		{7, 0x0000, 0x30, true}, // erase the bank
+	}
+	for i, w := range writes {
+		mbc.WriteByte(0x2000, w.bank)
+		if got := mbc.WriteByte(0x4000|w.addr&0x1FFF, w.data); got != w.changed {
+			t.Errorf("Write %d reported the save changed %v, want %v", i, got, w.changed)
+		}
+	}
+}
+
+func TestPocketCameraCapture(t *testing.T) {
+	mbc := NewPocketCamera(bankedROM(8), true, 8*0x4000)
+	mbc.WriteByte(0x4000, 0x10)
+	for reg := uint16(cameraMatrix); reg < cameraRegisters; reg += 3 {
+		mbc.WriteByte(0xA000+reg, 0x40)
+		mbc.WriteByte(0xA001+reg, 0x80)
+		mbc.WriteByte(0xA002+reg, 0xC0)
+	}
+	mbc.WriteByte(0xA000, 0x01)
+
+	if busy := mbc.ReadByte(0xA000) & 0x01; busy != 0 {
+		t.Fatal("Camera is still busy after capturing")
+	}
+
+	// the test pattern goes from black on the left to white on the right in the first row
+	mbc.WriteByte(0x4000, 0x00)
+	first := mbc.ReadByte(0xA000+cameraImageAddr)>>7 | mbc.ReadByte(0xA001+cameraImageAddr)>>7<<1
+	lastTile := 0xA000 + cameraImageAddr + 15*16
+	last := mbc.ReadByte(uint16(lastTile))&1 | mbc.ReadByte(uint16(lastTile+1))&1<<1
+	if first != 3 || last != 0 {
+		t.Errorf("Got shades %d and %d at the ends of the first row, want 3 and 0", first, last)
+	}
+}
@@ -1,44 +1,58 @@
 package cartridge
 
-import (
-	"io"
-	"log"
-)
+import "io"
 
 type MBC0 struct {
-	romBank []byte // MBC0 is only ROM so pretty simple
+	rom        banks // MBC0 is only ROM so pretty simple
+	ram        banks // the ram on ROM+RAM carts, always enabled as there's nothing to turn it on
+	hasBattery bool  // whether or not cart has battery
 }
 
 // LoadFile implements MemoryBankController.
-func (*MBC0) LoadFile(file io.Reader) error {
-	return nil
+func (m *MBC0) LoadFile(file io.Reader) error {
+	if !m.hasBattery {
+		return nil
+	}
+	return m.ram.load(file)
 }
 
 // SaveFile implements MemoryBankController.
-func (*MBC0) SaveFile(file io.Writer) error {
-	return nil
+func (m *MBC0) SaveFile(file io.Writer) error {
+	if !m.hasBattery {
+		return nil
+	}
+	return m.ram.save(file)
 }
 
-// NewMBC0 create and return a new MBC0
-func NewMBC0(rom []byte) *MBC0 {
+// NewMBC0 create and return a new MBC0, ramSize is 0 for carts without ram
+func NewMBC0(rom []byte, hasBattery bool, ramSize int) *MBC0 {
 	newMBC0 := new(MBC0)
-	newMBC0.romBank = rom[0x0000:0x8000]
+	newMBC0.rom = newROMBanks(rom, 0x8000, 0x4000)
+	newMBC0.ram = newRAMBanks(min(ramSize, 0x2000), 0x2000) // there's no banking so only 8KB can be reached
+	newMBC0.hasBattery = hasBattery && ramSize > 0
 
 	return newMBC0
 }
 
 // ReadByte Read byte at given address and return it
 func (m *MBC0) ReadByte(addr uint16) byte {
-	if addr < 0 || addr >= 0x8000 {
-		log.Fatalf("Bank attempted to read from address %v out of range", addr) // TODO - check if this needs to be fatal
+	switch {
+	case addr <= 0x7FFF:
+		return m.rom.read(int(addr/0x4000), addr)
+	case addr >= 0xA000 && addr <= 0xBFFF && !m.ram.empty():
+		return m.ram.read(0, addr-0xA000)
 	}
 
-	return m.romBank[addr]
+	return 0xFF
 }
 
-// WriteByte write given data to addr
-func (m *MBC0) WriteByte(addr uint16, data byte) {
-	// log.Println("Unable to write to MBC0, no RAM to write to")
+// WriteByte write given data to addr, only the ram can be written
+// Returns whether the save changed
+func (m *MBC0) WriteByte(addr uint16, data byte) bool {
+	if addr >= 0xA000 && addr <= 0xBFFF && !m.ram.empty() {
+		return m.ram.write(0, addr-0xA000, data)
+	}
+	return false
 }
 
 // switchROMBank does nothing for MBC0
@@ -53,5 +67,5 @@ func (m *MBC0) switchRAMBank(bank int) {
 
 // HasBattery return whether or not MBC supports battery
 func (m *MBC0) HasBattery() bool {
-	return false
+	return m.hasBattery
 }
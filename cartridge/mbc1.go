@@ -1,31 +1,30 @@
 package cartridge
 
-import "io"
-
-////////////
-// TODO's //
-////////////
-//
-// TODO - check whatever the hell a "mode flag" and a "Zero bank" is (https://hacktix.github.io/GBEDG/mbcs/mbc1/#zero-bank)
-// TODO - possibly implement the like large banks which has stuff like the mode flag
+import (
+	"bytes"
+	"io"
+)
 
 const (
-	sixteenMBRom8KBRam = iota
-	fourMBRom32KBRam
+	sixteenMBRom8KBRam = iota // mode 0 - 0x0000-0x3FFF is always bank 0 and RAM is always bank 0
+	fourMBRom32KBRam          // mode 1 - the second bank register also switches 0x0000-0x3FFF and the RAM bank
 )
 
+// multicartSize the size of MBC1M multicarts, they're all 1MiB with 4 256KiB games
+const multicartSize = 0x100000
+
 type MBC1 struct {
 	name       string
-	mode       uint8 // The banking mode (0 for default/ 16mbROM with 8kbRAM - 1 for 4mbROM with 32KBRAM) - TODO CHECK THIS
+	mode       uint8 // The banking mode, see sixteenMBRom8KBRam and fourMBRom32KBRam
 	hasBattery bool
+	multicart  bool // whether the cart is wired as a MBC1M multicart (bank2 shifted by 4 instead of 5)
 
-	romBank0 []byte // contains first 16kib of the cart ROM
-	romBanks [][]byte
-	romBank  int // the active rom Bank
-	ROMSize  int // the rom size
+	rom     banks // every rom bank including bank 0
+	bank1   byte  // the first bank register (0x2000-0x3FFF) - the lower 5 bits of the rom bank, never 0
+	bank2   byte  // the second bank register (0x4000-0x5FFF) - the upper 2 bits of the rom bank or the ram bank
+	ROMSize int   // the rom size
 
-	ramBanks   [][]byte
-	ramBank    int  // the active RAM bank
+	ram        banks
 	hasRAM     bool // whether or not the MBC1 has ram or not (cuz like there's some that don't have ram)
 	ramEnabled bool // bool for if RAM is enabled or not
 	RAMSize    int  // the ram size
@@ -36,10 +35,7 @@ func (m *MBC1) LoadFile(file io.Reader) error {
 	if !m.hasBattery {
 		return nil
 	}
-	var err error = nil
-
-	m.ramBanks, err = readRamFromFile(file, len(m.ramBanks), 0x2000)
-	return err
+	return m.ram.load(file)
 }
 
 // SaveFile implements MemoryBankController.
@@ -48,7 +44,7 @@ func (m *MBC1) SaveFile(file io.Writer) error {
 		return nil
 	}
 
-	return writeRAMToFile(m.ramBanks, file)
+	return m.ram.save(file)
 }
 
 // NewMBC1 create a new MBC1 from specifications
@@ -59,39 +55,88 @@ func NewMBC1(rom []byte, romSize, ramSize int, hasBattery bool) *MBC1 {
 	newMBC.mode = sixteenMBRom8KBRam
 	newMBC.hasBattery = hasBattery
 	newMBC.ROMSize, newMBC.RAMSize = romSize, ramSize
+	newMBC.multicart = isMBC1Multicart(rom, romSize)
 
 	if ramSize > 0 { // enable ram stuff if ram supported
 		newMBC.hasRAM = true
-		newMBC.ramEnabled = true
-		newMBC.ramBank = 0
-		newMBC.ramBanks = createRAMBanks(4)
+		newMBC.ram = newRAMBanks(ramSize, 0x2000)
 	}
 
-	newMBC.romBank = 0
-	newMBC.romBank0 = rom[0x0000:0x4000]
-	newMBC.romBanks = createROMBanks(rom, romSize/0x4000)
+	newMBC.bank1 = 1
+	newMBC.rom = newROMBanks(rom, romSize, 0x4000)
 
 	return newMBC
 }
 
+// isMBC1Multicart guess whether rom is a MBC1M multicart, there's nothing in the
+// header for it so look for the Nintendo logo at the start of more than one of the games
+func isMBC1Multicart(rom []byte, romSize int) bool {
+	if romSize != multicartSize || len(rom) < multicartSize {
+		return false
+	}
+
+	logos := 0
+	for game := 0; game < 4; game++ {
+		start := game*0x40000 + 0x0104
+		if bytes.Equal(rom[start:start+len(nintendoLogo)], nintendoLogo) {
+			logos++
+		}
+	}
+
+	return logos >= 2
+}
+
+// IsMulticart return whether the cart was detected as a MBC1M multicart
+func (m *MBC1) IsMulticart() bool {
+	return m.multicart
+}
+
+// upperBank the bits bank2 adds on to the rom bank
+func (m *MBC1) upperBank() int {
+	if m.multicart {
+		return int(m.bank2) << 4
+	}
+	return int(m.bank2) << 5
+}
+
+// romBank the rom bank mapped to 0x4000-0x7FFF, it's masked to the rom size when read
+func (m *MBC1) romBank() int {
+	lower := int(m.bank1)
+	if m.multicart { // bit 4 of bank1 isn't connected but is still checked for 0
+		lower &= 0x0F
+	}
+	return m.upperBank() | lower
+}
+
+// zeroBank the rom bank mapped to 0x0000-0x3FFF
+func (m *MBC1) zeroBank() int {
+	if m.mode == sixteenMBRom8KBRam {
+		return 0
+	}
+	return m.upperBank()
+}
+
+// ramBank the active ram bank, only mode 1 can switch it
+func (m *MBC1) ramBank() int {
+	if m.mode == sixteenMBRom8KBRam {
+		return 0
+	}
+	return int(m.bank2)
+}
+
 // ReadByte read Byte from addr
 func (m *MBC1) ReadByte(addr uint16) byte {
 	if addr <= 0x3FFF {
-		return m.romBank0[addr] // TODO - check the zero bank thing (top of page)
+		return m.rom.read(m.zeroBank(), addr)
 	}
 
 	if addr >= 0x4000 && addr <= 0x7FFF {
-		return m.romBanks[m.romBank][addr-0x4000]
+		return m.rom.read(m.romBank(), addr-0x4000)
 	}
 
 	if addr >= 0xA000 && addr <= 0xBFFF {
 		if m.hasRAM && m.ramEnabled {
-			switch m.mode {
-			case fourMBRom32KBRam:
-				return m.ramBanks[m.ramBank][addr-0xA000]
-			case sixteenMBRom8KBRam:
-				return m.ramBanks[0][addr-0xA000]
-			}
+			return m.ram.read(m.ramBank(), addr-0xA000)
 		}
 	}
 
@@ -99,47 +144,40 @@ func (m *MBC1) ReadByte(addr uint16) byte {
 }
 
 // WriteByte write given data to addr
-func (m *MBC1) WriteByte(addr uint16, data byte) {
+// Returns whether the save changed
+func (m *MBC1) WriteByte(addr uint16, data byte) bool {
 	switch {
 	case addr <= 0x1FFF && m.hasRAM: // enable or disable RAM
-		if data&0x0F == 0x0A { // TODO - check that I don't need to check the mode
-			// log.Printf("%s: Enabling RAM...\n", m.name)
-			m.ramEnabled = true
-		} else {
-			// log.Printf("%s: DIsabling RAM...\n", m.name)
-			m.ramEnabled = false
-		}
+		m.ramEnabled = data&0x0F == 0x0A
 
 	case addr >= 0x2000 && addr <= 0x3FFF: // switch ROM bank
-		m.switchROMBank(int(data & 0x1F)) // TODO - check if I need to do any other masking for sizes
+		m.switchROMBank(int(data))
 
-	case addr >= 0x4000 && addr <= 0x5FFF: // switch RAM bank
-		m.switchRAMBank(int(data & 0x03))
+	case addr >= 0x4000 && addr <= 0x5FFF: // switch RAM bank (or upper ROM bank bits)
+		m.switchRAMBank(int(data))
 
 	case addr >= 0x6000 && addr <= 0x7FFF: // mode select
 		m.mode = data & 0x1
-		// TODO - check
 
-	case addr >= 0xA000 && addr <= 0xBFFF: // External RAM - TODO check this is right
+	case addr >= 0xA000 && addr <= 0xBFFF: // External RAM
 		if m.hasRAM && m.ramEnabled {
-			switch m.mode {
-			case fourMBRom32KBRam:
-				m.ramBanks[m.ramBank][addr-0xA000] = data
-			case sixteenMBRom8KBRam:
-				m.ramBanks[0][addr-0xA000] = data
-			}
+			return m.ram.write(m.ramBank(), addr-0xA000, data)
 		}
 	}
+	return false
 }
 
-// switchROMBank switch active rom bank to val
+// switchROMBank set the first bank register to the lower 5 bits of bank, 0 is treated as 1
 func (m *MBC1) switchROMBank(bank int) {
-	m.romBank = bank
+	m.bank1 = byte(bank & 0x1F)
+	if m.bank1 == 0 {
+		m.bank1 = 1
+	}
 }
 
-// switchRAMBank switch active ram bank to val
+// switchRAMBank set the second bank register to the lower 2 bits of bank
 func (m *MBC1) switchRAMBank(bank int) {
-	m.ramBank = bank
+	m.bank2 = byte(bank & 0x03)
 }
 
 // HasBattery return whether or not MBC supports battery
@@ -0,0 +1,129 @@
+package cartridge
+
+import "testing"
+
+// bankedROM make a rom with banks 16KiB banks where the first byte of each bank is its number
+func bankedROM(banks int) []byte {
+	rom := make([]byte, banks*0x4000)
+	for bank := 0; bank < banks; bank++ {
+		rom[bank*0x4000] = byte(bank)
+	}
+	return rom
+}
+
+func TestMBC1Banking(t *testing.T) {
+	type write struct {
+		addr uint16
+		data byte
+	}
+
+	tests := []struct {
+		name     string
+		banks    int
+		writes   []write
+		wantZero byte // the bank at 0x0000
+		wantBank byte // the bank at 0x4000
+	}{
+		{"bank 0 is 1", 128, []write{{0x2000, 0x00}}, 0x00, 0x01},
+		{"bank1 is 5 bits", 128, []write{{0x2000, 0x25}}, 0x00, 0x05},
+		{"bank1 0 check is 5 bits", 128, []write{{0x2000, 0x20}}, 0x00, 0x01},
+		{"bank2 upper bits", 128, []write{{0x4000, 0x02}, {0x2000, 0x00}}, 0x00, 0x41},
+		{"mode 1 switches bank 0", 128, []write{{0x6000, 0x01}, {0x4000, 0x03}, {0x2000, 0x04}}, 0x60, 0x64},
+		{"mode 1 small rom", 32, []write{{0x6000, 0x01}, {0x4000, 0x01}, {0x2000, 0x02}}, 0x00, 0x02},
+		{"bank wraps to rom size", 8, []write{{0x2000, 0x0A}}, 0x00, 0x02},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mbc := NewMBC1(bankedROM(test.banks), test.banks*0x4000, 0, false)
+			for _, w := range test.writes {
+				mbc.WriteByte(w.addr, w.data)
+			}
+
+			if got := mbc.ReadByte(0x0000); got != test.wantZero {
+				t.Errorf("Got bank 0x%02X at 0x0000, want 0x%02X", got, test.wantZero)
+			}
+			if got := mbc.ReadByte(0x4000); got != test.wantBank {
+				t.Errorf("Got bank 0x%02X at 0x4000, want 0x%02X", got, test.wantBank)
+			}
+		})
+	}
+}
+
+func TestMBC1RAM32KB(t *testing.T) {
+	mbc := NewMBC1(bankedROM(4), 4*0x4000, 0x8000, false)
+	mbc.WriteByte(0x0000, 0x0A)
+
+	for bank := byte(0); bank < 4; bank++ {
+		mbc.WriteByte(0x4000, bank)
+		mbc.WriteByte(0xA000, bank+1) // mode 0 always uses bank 0
+	}
+	if got := mbc.ReadByte(0xA000); got != 4 {
+		t.Fatalf("Got 0x%02X in mode 0, want 0x04", got)
+	}
+
+	mbc.WriteByte(0x6000, 0x01)
+	for bank := byte(0); bank < 4; bank++ {
+		mbc.WriteByte(0x4000, bank)
+		mbc.WriteByte(0xA000, 0x10+bank)
+	}
+	for bank := byte(0); bank < 4; bank++ {
+		mbc.WriteByte(0x4000, bank)
+		if got := mbc.ReadByte(0xA000); got != 0x10+bank {
+			t.Errorf("Got 0x%02X in RAM bank %d, want 0x%02X", got, bank, 0x10+bank)
+		}
+	}
+
+	mbc.WriteByte(0x0000, 0x00)
+	if got := mbc.ReadByte(0xA000); got != 0xFF {
+		t.Errorf("Got 0x%02X with RAM disabled, want 0xFF", got)
+	}
+}
+
+func TestMBC1Multicart(t *testing.T) {
+	rom := bankedROM(64)
+	for game := 0; game < 4; game++ {
+		copy(rom[game*0x40000+0x0104:], nintendoLogo)
+	}
+
+	mbc := NewMBC1(rom, len(rom), 0, false)
+	if !mbc.IsMulticart() {
+		t.Fatal("Multicart wasn't detected")
+	}
+
+	mbc.WriteByte(0x4000, 0x01)
+	mbc.WriteByte(0x2000, 0x12) // bit 4 isn't connected
+	if got := mbc.ReadByte(0x4000); got != 0x12 {
+		t.Errorf("Got bank 0x%02X, want 0x12", got)
+	}
+
+	mbc.WriteByte(0x2000, 0x10) // only 0 is bumped to 1, 0x10 isn't
+	if got := mbc.ReadByte(0x4000); got != 0x10 {
+		t.Errorf("Got bank 0x%02X, want 0x10", got)
+	}
+
+	mbc.WriteByte(0x6000, 0x01)
+	mbc.WriteByte(0x4000, 0x03)
+	if got := mbc.ReadByte(0x0000); got != 0x30 {
+		t.Errorf("Got bank 0x%02X at 0x0000, want 0x30", got)
+	}
+
+	if NewMBC1(bankedROM(64), 64*0x4000, 0, false).IsMulticart() {
+		t.Error("Plain 1MiB rom detected as a multicart")
+	}
+}
+
+func TestMBC1RAMWriteChangesSave(t *testing.T) {
+	mbc := NewMBC1(bankedROM(4), 4*0x4000, 0x2000, true)
+	if mbc.WriteByte(0xA000, 0x42) {
+		t.Error("Write with the ram disabled reported the save changed")
+	}
+
+	mbc.WriteByte(0x0000, 0x0A)
+	if !mbc.WriteByte(0xA000, 0x42) {
+		t.Error("Write of a new value didn't report the save changed")
+	}
+	if mbc.WriteByte(0xA000, 0x42) {
+		t.Error("Write of the same value reported the save changed")
+	}
+}
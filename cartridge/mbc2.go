@@ -7,16 +7,17 @@ import "io"
 ///////////////
 //
 // 256KB ROM
-// 512x4 RAM
+// 512x4 RAM - mirrored across 0xA000-0xBFFF, the upper 4 bits aren't connected and read as 1s
+//
+// Only address bit 8 is decoded for the registers so they're both mirrored across 0x0000-0x3FFF
 
 type MBC2 struct {
 	name        string
 	romSize     int       // the size of the rom
-	romBank0    []byte    // contains first 16kib of ROM
-	romBanks    [][]byte  // the other rom banks
-	romBank     byte      // Currently selected ROM bank number
+	rom         banks     // every rom bank including bank 0
+	romBank     byte      // Currently selected ROM bank number (never 0)
 	hasRAM      bool      // whether or not cart has RAM
-	externalRam [512]byte // the internal RAM on the cart (is represented in half-bytes, so only bottom 4 bytes are used)
+	externalRam [512]byte // the internal RAM on the cart (is represented in half-bytes, the upper 4 bits are always set like other emulators save it)
 	RamEnabled  bool      // whether or not RAM is enabled
 	hasBattery  bool      // whether or not cart has battery buffered ram
 }
@@ -26,11 +27,14 @@ func (m *MBC2) LoadFile(file io.Reader) error {
 	if !m.hasBattery {
 		return nil
 	}
-	var err error = nil
+	if _, err := io.ReadFull(file, m.externalRam[:]); err != nil {
+		return err
+	}
 
-	banks, err := readRamFromFile(file, 1, 512)
-	m.externalRam = [512]byte(banks[0])
-	return err
+	for i, b := range m.externalRam {
+		m.externalRam[i] = b | 0xF0
+	}
+	return nil
 }
 
 // SaveFile implements MemoryBankController.
@@ -39,7 +43,8 @@ func (m *MBC2) SaveFile(file io.Writer) error {
 		return nil
 	}
 
-	return writeRAMToFile([][]byte{m.externalRam[:]}, file)
+	_, err := file.Write(m.externalRam[:])
+	return err
 }
 
 // NewMBC2 create, map and return a new MBC2
@@ -52,9 +57,12 @@ func NewMBC2(rom []byte, romSize int, hasBattery bool) *MBC2 {
 	mbc.hasRAM = true
 	mbc.RamEnabled = true
 
-	mbc.romBank0 = rom[0x0000:0x4000]
-	mbc.romBanks = createROMBanks(rom, romSize/0x4000)
-	mbc.romBank = 0
+	for i := range mbc.externalRam {
+		mbc.externalRam[i] = 0xFF
+	}
+
+	mbc.rom = newROMBanks(rom, romSize, 0x4000)
+	mbc.romBank = 1
 	return mbc
 }
 
@@ -63,46 +71,49 @@ func (m *MBC2) switchRAMBank(bank int) {
 
 }
 
-// switchROMBank UNUSED, done in regular functions
+// switchROMBank switch to the rom bank in the lower 4 bits of bank, 0 is treated as 1
 func (m *MBC2) switchROMBank(bank int) {
-
+	m.romBank = byte(bank & 0x0F)
+	if m.romBank == 0 {
+		m.romBank = 1
+	}
 }
 
 // WriteByte write given byte to addr location
-func (m *MBC2) WriteByte(addr uint16, data byte) {
+// Returns whether the save changed
+func (m *MBC2) WriteByte(addr uint16, data byte) bool {
 	switch {
 	case addr >= 0x0000 && addr <= 0x3FFF: // enable RAM / ROM bank number
 		if (addr>>8)&0x01 == 0x01 { // switch ROM bank num
-			m.romBank = data & 0x0F
+			m.switchROMBank(int(data))
 		} else {
-			if data&0x0F == 0xA {
-				m.RamEnabled = true
-			} else {
-				m.RamEnabled = false
-			}
+			m.RamEnabled = data&0x0F == 0xA
 		}
 
 	case addr >= 0xA000 && addr <= 0xBFFF: // eternal ram
 		if !m.RamEnabled || !m.hasRAM {
-			return
+			return false
 		}
 
-		m.externalRam[addr&0x1FF] = data & 0x0F
+		changed := m.externalRam[addr&0x1FF] != data|0xF0
+		m.externalRam[addr&0x1FF] = data | 0xF0
+		return changed
 	}
+	return false
 }
 
 // ReadByte read the byte from the MBC2 memory map
 func (m *MBC2) ReadByte(addr uint16) byte {
 	switch {
 	case addr >= 0x0000 && addr <= 0x3FFF: // ROM Bank 0
-		return m.romBank0[addr]
+		return m.rom.read(0, addr)
 	case addr >= 0x4000 && addr <= 0x7FFF: // selectable rom bank (mapped using 0x4000 * RomBankNum + (addr - 0x4000))
-		return m.romBanks[m.romBank][addr-0x4000]
+		return m.rom.read(int(m.romBank), addr-0x4000)
 	case addr >= 0xA000 && addr <= 0xBFFF: // external RAM
 		if !m.RamEnabled || !m.hasRAM {
 			return 0xFF
 		}
-		return m.externalRam[addr&0x1FF] | 0xF0
+		return m.externalRam[addr&0x1FF]
 	}
 	return 0xFF
 }
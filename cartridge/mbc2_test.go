@@ -0,0 +1,72 @@
+package cartridge
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestMBC2Registers(t *testing.T) {
+	mbc := NewMBC2(bankedROM(8), 8*0x4000, false)
+
+	for _, bank := range []byte{0x00, 0x10} { // 0 is treated as 1, only 4 bits are used
+		mbc.WriteByte(0x2100, bank)
+		if got := mbc.ReadByte(0x4000); got != 1 {
+			t.Errorf("Got bank %d after writing 0x%02X, want 1", got, bank)
+		}
+	}
+
+	mbc.WriteByte(0x3FFF, 0x05) // bit 8 set anywhere in 0x0000-0x3FFF selects the rom bank
+	if got := mbc.ReadByte(0x4000); got != 5 {
+		t.Errorf("Got bank %d, want 5", got)
+	}
+
+	mbc.WriteByte(0x010F, 0x0C) // 12 wraps to 4 on a 128KiB rom
+	if got := mbc.ReadByte(0x4000); got != 4 {
+		t.Errorf("Got bank %d, want 4", got)
+	}
+
+	mbc.WriteByte(0x2000, 0x00) // bit 8 clear is the ram enable even above 0x2000
+	mbc.WriteByte(0xA000, 0x03)
+	if got := mbc.ReadByte(0xA000); got != 0xFF {
+		t.Errorf("Got 0x%02X with RAM disabled, want 0xFF", got)
+	}
+	mbc.WriteByte(0x3EFF, 0x0A)
+	mbc.WriteByte(0xA000, 0x03)
+	if got := mbc.ReadByte(0xA000); got != 0xF3 {
+		t.Errorf("Got 0x%02X with RAM enabled, want 0xF3", got)
+	}
+}
+
+func TestMBC2RAM(t *testing.T) {
+	mbc := NewMBC2(bankedROM(16), 16*0x4000, true)
+	mbc.WriteByte(0x0000, 0x0A)
+
+	mbc.WriteByte(0xA123, 0xA5)
+	for _, addr := range []uint16{0xA123, 0xA323, 0xB123, 0xBF23} { // mirrored every 512 bytes
+		if got := mbc.ReadByte(addr); got != 0xF5 {
+			t.Errorf("Got 0x%02X at 0x%04X, want 0xF5", got, addr)
+		}
+	}
+
+	var save bytes.Buffer
+	if err := mbc.SaveFile(&save); err != nil {
+		t.Fatalf("Failed to save: %v", err)
+	}
+	if save.Len() != 512 || save.Bytes()[0x123] != 0xF5 {
+		t.Fatalf("Got a %d byte save with 0x%02X at 0x123, want 512 bytes with 0xF5", save.Len(), save.Bytes()[0x123])
+	}
+
+	loaded := NewMBC2(bankedROM(16), 16*0x4000, true)
+	save.Bytes()[0x123] = 0x05 // saves without the upper bits set still load
+	if err := loaded.LoadFile(&save); err != nil {
+		t.Fatalf("Failed to load: %v", err)
+	}
+	loaded.WriteByte(0x0000, 0x0A)
+	if got := loaded.ReadByte(0xA123); got != 0xF5 {
+		t.Errorf("Got 0x%02X after loading, want 0xF5", got)
+	}
+
+	if err := loaded.LoadFile(bytes.NewReader(nil)); err == nil {
+		t.Error("Expected an error loading an empty save")
+	}
+}
@@ -4,13 +4,12 @@ import "io"
 
 type MBC3 struct {
 	romSize    int      // the rom size
-	romBanks   [][]byte // the rom banks (max 128)
-	romBank0   []byte   // rom bank 0 (first 16KIB of rom)
-	romBank    byte     // the current rom bank (7 bits)
+	rom        banks    // the rom banks (max 128) including bank 0
+	romBank    byte     // the current rom bank (7 bits, never 0)
 	hasRam     bool     // whether or not cart supports ram
 	ramSize    int      // the ram size
-	ramBanks   [][]byte // the RAM banks (max 4)
-	ramBank    byte     // the current RAM bank (2 bits)
+	ram        banks    // the RAM banks (max 4, 8 for MBC30)
+	ramBank    byte     // the current RAM bank (3 bits)
 	ramEnabled bool     // whether or not ram and RTC are enabled
 	hasTimer   bool     // whether or not MBC3 has a timer
 	hasBattery bool     // whether or not has battery
@@ -23,10 +22,7 @@ func (m *MBC3) LoadFile(file io.Reader) error {
 	if !m.hasBattery {
 		return nil
 	}
-	var err error = nil
-
-	m.ramBanks, err = readRamFromFile(file, len(m.ramBanks), 0x2000)
-	return err
+	return m.ram.load(file)
 }
 
 // SaveFile implements MemoryBankController.
@@ -35,7 +31,7 @@ func (m *MBC3) SaveFile(file io.Writer) error {
 		return nil
 	}
 
-	return writeRAMToFile(m.ramBanks, file)
+	return m.ram.save(file)
 }
 
 // NewMBC3 create and return a new MBC3, populating the banks
@@ -49,12 +45,11 @@ func NewMBC3(rom []byte, hasBattery, hasTimer bool, ramSize, romSize int) *MBC3
 		mbc.hasRam = true
 		mbc.ramEnabled = true
 		mbc.ramBank = 0
-		mbc.ramBanks = createRAMBanks(4) // TODO - check it's always 4		
+		mbc.ram = newRAMBanks(ramSize, 0x2000)
 	}
 
-	mbc.romBank = 0
-	mbc.romBank0 = rom[0x0000:0x4000]
-	mbc.romBanks = createROMBanks(rom, romSize/0x4000)
+	mbc.romBank = 1
+	mbc.rom = newROMBanks(rom, romSize, 0x4000)
 
 	return mbc
 }
@@ -63,10 +58,10 @@ func NewMBC3(rom []byte, hasBattery, hasTimer bool, ramSize, romSize int) *MBC3
 func (m *MBC3) ReadByte(addr uint16) byte {
 	switch {
 	case addr >= 0x0000 && addr <= 0x3FFF: // Rom Bank 0
-		return m.romBank0[addr]
+		return m.rom.read(0, addr)
 
 	case addr >= 0x4000 && addr <= 0x7FFF: // switchable rom banks
-		return m.romBanks[m.romBank][addr-0x4000]
+		return m.rom.read(int(m.romBank), addr-0x4000)
 
 	case addr >= 0xA000 && addr <= 0xC000: // external Ram/ RTC
 		if m.rtcMapped && m.hasTimer {
@@ -74,7 +69,7 @@ func (m *MBC3) ReadByte(addr uint16) byte {
 		}
 
 		if m.ramEnabled && m.hasRam {
-			return m.ramBanks[m.ramBank][addr-0xA000]
+			return m.ram.read(int(m.ramBank), addr-0xA000)
 		}
 		return 0xFF
 	}
@@ -83,7 +78,8 @@ func (m *MBC3) ReadByte(addr uint16) byte {
 }
 
 // WriteByte write byte to addr in MBC3
-func (m *MBC3) WriteByte(addr uint16, data byte) {
+// Returns whether the save changed
+func (m *MBC3) WriteByte(addr uint16, data byte) bool {
 	switch {
 	case addr >= 0x0000 && addr <= 0x1FFF: // enable ram/ timer registers
 		if data&0x0F == 0xA {
@@ -94,16 +90,19 @@ func (m *MBC3) WriteByte(addr uint16, data byte) {
 
 	case addr >= 0x2000 && addr <= 0x3FFF: // ROM bank low
 		m.romBank = data & 0x7F
+		if m.romBank == 0 {
+			m.romBank = 1
+		}
 
 	case addr >= 0x4000 && addr <= 0x5FFF: // Ram Bank/ RTC Reg select
 		switch {
-		case addr >= 0x00 && addr <= 0x03: // set RAM Banks
+		case data <= 0x07: // set RAM Banks (only MBC30 has 0x04-0x07)
 			if m.hasRam {
-				m.ramBank = data & 0x03
+				m.ramBank = data & 0x07
 				m.rtcMapped = false
 			}
 
-		case addr >= 0x08 && addr <= 0x0C: // RTC Map
+		case data >= 0x08 && data <= 0x0C: // RTC Map
 			// TODO - write about mapping the given RTC register
 			m.rtcMapped = true
 		}
@@ -113,15 +112,16 @@ func (m *MBC3) WriteByte(addr uint16, data byte) {
 
 	case addr >= 0xA000 && addr <= 0xBFFF: // EXternal RAM / RTC reg write
 		if !m.ramEnabled {
-			return
+			return false
 		}
 
 		if m.rtcMapped {
 			m.rtc.writeByte(addr, data)
 		} else if m.hasRam {
-			m.ramBanks[m.ramBank][addr-0xA000] = data
+			return m.ram.write(int(m.ramBank), addr-0xA000, data)
 		}
 	}
+	return false
 }
 
 // SwitchRAMBank switch ram bank
@@ -4,14 +4,18 @@ import "io"
 
 type MBC5 struct {
 	romSize    int      // the rom size
-	romBanks   [][]byte // the rom banks (allows for 512 of them and no individual bank0 field as bank0 is here)
+	rom        banks    // the rom banks (allows for 512 of them and no individual bank0 field as bank0 is here)
 	romBank    uint16   // the active rom bank
 	hasRAM     bool     // whether or not cart has RAM
 	ramSize    int      // the RAM size (if any)
-	ramBanks   [][]byte // the ram banks
+	ram        banks    // the ram banks
 	ramBank    byte     // the active ram bank
 	ramEnabled bool     // whether or not the ram is enabled
 	hasBattery bool     // whether or not cart has battery
+	hasRumble  bool     // whether or not cart has a rumble motor, bit 3 of the ram bank turns it on
+	rumbling   bool     // whether or not the rumble motor is on
+
+	OnRumble func(on bool) // called when the rumble motor turns on or off
 }
 
 // LoadFile implements MemoryBankController.
@@ -19,10 +23,7 @@ func (m *MBC5) LoadFile(file io.Reader) error {
 	if !m.hasBattery {
 		return nil
 	}
-	var err error = nil
-
-	m.ramBanks, err = readRamFromFile(file, len(m.ramBanks), 0x2000)
-	return err
+	return m.ram.load(file)
 }
 
 // SaveFile implements MemoryBankController.
@@ -31,24 +32,25 @@ func (m *MBC5) SaveFile(file io.Writer) error {
 		return nil
 	}
 
-	return writeRAMToFile(m.ramBanks, file)
+	return m.ram.save(file)
 }
 
 // NewMBC5 create and return a new MBC5
-func NewMBC5(rom []byte, hasBattery bool, ramSize, romSize int) *MBC5 {
+func NewMBC5(rom []byte, hasBattery, hasRumble bool, ramSize, romSize int) *MBC5 {
 	mbc := new(MBC5)
 	mbc.hasBattery = hasBattery
+	mbc.hasRumble = hasRumble
 	mbc.romSize, mbc.ramSize = romSize, ramSize
 
 	if ramSize > 0 {
 		mbc.hasRAM = true
 		mbc.ramEnabled = true
 		mbc.ramBank = 0
-		mbc.ramBanks = createRAMBanks(16) // TODO - maybe change to use ramSize
+		mbc.ram = newRAMBanks(ramSize, 0x2000)
 	}
 
-	mbc.romBank = 0
-	mbc.romBanks = createROMBanksFromZero(rom, romSize/0x4000)
+	mbc.romBank = 1 // bank 1 is mapped at power on
+	mbc.rom = newROMBanks(rom, romSize, 0x4000)
 
 	return mbc
 }
@@ -57,26 +59,27 @@ func NewMBC5(rom []byte, hasBattery bool, ramSize, romSize int) *MBC5 {
 func (m *MBC5) ReadByte(addr uint16) byte {
 	switch {
 	case addr >= 0x0000 && addr <= 0x3FFF: // rom bank 0
-		return m.romBanks[0][addr]
+		return m.rom.read(0, addr)
 	case addr >= 0x4000 && addr <= 0x7FFF: // Switchable rom bank
-		return m.romBanks[m.romBank][addr-0x4000]
+		return m.rom.read(int(m.romBank), addr-0x4000)
 	case addr >= 0xA000 && addr <= 0xBFFF: // switchable ram bank (if enabled)
 		if !m.hasRAM || !m.ramEnabled {
 			return 0xFF
 		}
 
-		return m.ramBanks[m.ramBank][addr-0xA000]
+		return m.ram.read(int(m.ramBank), addr-0xA000)
 	}
 
 	return 0xFF
 }
 
 // WriteByte write given data to addr
-func (m *MBC5) WriteByte(addr uint16, data byte) {
+// Returns whether the save changed
+func (m *MBC5) WriteByte(addr uint16, data byte) bool {
 	switch {
 	case addr >= 0x0000 && addr <= 0x1FFF: // Ram enable
 		if !m.hasRAM {
-			return
+			return false
 		}
 
 		if data&0x0F == 0x0A {
@@ -92,15 +95,21 @@ func (m *MBC5) WriteByte(addr uint16, data byte) {
 		m.romBank = (m.romBank & 0xFF) | ((uint16(data) & 0x01) << 8)
 
 	case addr >= 0x4000 && addr <= 0x5FFF: // set ram bank value
-		m.ramBank = data
+		if m.hasRumble { // bit 3 is wired to the motor instead of the ram
+			m.setRumble(data&0x08 != 0)
+			m.ramBank = data & 0x07
+		} else {
+			m.ramBank = data & 0x0F
+		}
 
 	case addr >= 0xA000 && addr <= 0xBFFF: // write to external ram
 		if !m.hasRAM || !m.ramEnabled {
-			return
+			return false
 		}
 
-		m.ramBanks[m.ramBank][addr-0xA000] = data
+		return m.ram.write(int(m.ramBank), addr-0xA000, data)
 	}
+	return false
 }
 
 // HasBattery return whether or not MBC supports battery
@@ -108,6 +117,23 @@ func (m *MBC5) HasBattery() bool {
 	return m.hasBattery
 }
 
+// HasRumble return whether or not the cart has a rumble motor
+func (m *MBC5) HasRumble() bool {
+	return m.hasRumble
+}
+
+// setRumble turn the rumble motor on or off and tell OnRumble if it changed
+func (m *MBC5) setRumble(on bool) {
+	if on == m.rumbling {
+		return
+	}
+
+	m.rumbling = on
+	if m.OnRumble != nil {
+		m.OnRumble(on)
+	}
+}
+
 // SwitchRAMBank switch ram bank
 func (m *MBC5) switchRAMBank(bank int) {
 
@@ -0,0 +1,38 @@
+package cartridge
+
+import "testing"
+
+func TestMBC5Rumble(t *testing.T) {
+	mbc := NewMBC5(bankedROM(4), false, true, 0x20000, 4*0x4000)
+	var changes []bool
+	mbc.OnRumble = func(on bool) { changes = append(changes, on) }
+
+	mbc.WriteByte(0x0000, 0x0A)
+	mbc.WriteByte(0x4000, 0x0B) // motor on, ram bank 3
+	mbc.WriteByte(0xA000, 0x42)
+	mbc.WriteByte(0x4000, 0x0B) // no change so no callback
+	mbc.WriteByte(0x4000, 0x03) // motor off, same ram bank
+
+	if got := mbc.ReadByte(0xA000); got != 0x42 {
+		t.Errorf("Got 0x%02X, the motor bit changed the ram bank", got)
+	}
+	if len(changes) != 2 || !changes[0] || changes[1] {
+		t.Errorf("Got rumble changes %v, want [true false]", changes)
+	}
+
+	plain := NewMBC5(bankedROM(4), false, false, 0x20000, 4*0x4000)
+	plain.OnRumble = func(on bool) { t.Error("Rumble on a cart without a motor") }
+	plain.WriteByte(0x4000, 0x08)
+}
+
+func TestMBC5PowerOnBank(t *testing.T) {
+	mbc := NewMBC5(bankedROM(4), false, false, 0, 4*0x4000)
+	if got := mbc.ReadByte(0x4000); got != 1 {
+		t.Errorf("0x4000 read bank %d at power on, want bank 1", got)
+	}
+
+	mbc.WriteByte(0x2000, 0x00) // unlike the other MBCs bank 0 can be mapped
+	if got := mbc.ReadByte(0x4000); got != 0 {
+		t.Errorf("0x4000 read bank %d after selecting bank 0, want bank 0", got)
+	}
+}
@@ -0,0 +1,240 @@
+package cartridge
+
+import (
+	"errors"
+	"io"
+)
+
+///////////////
+// MBC6 Cart //
+///////////////
+//
+// Only used by Net de Get. The switchable rom area is split into two 8KiB halves
+// (0x4000-0x5FFF and 0x6000-0x7FFF) that can each map a rom bank or a bank of the
+// 1MiB flash chip, and the ram area is split into two 4KiB halves.
+//
+// The flash takes the usual 0xAA 0x55 unlock then command writes. Erasing clears
+// the whole 8KiB bank the address is in rather than the chips real sector size.
+
+const (
+	mbc6FlashSize    = 0x100000 // the size of the flash chip
+	mbc6RAMSize      = 0x8000   // the size of the ram, it's always 32KiB
+	mbc6RAMBankSize  = 0x1000
+	mbc6ROMBankSize  = 0x2000
+	mbc6FlashUnlock1 = 0x5555 // the flash address the first unlock byte (0xAA) is written to
+	mbc6FlashUnlock2 = 0x2AAA // the flash address the second unlock byte (0x55) is written to
+)
+
+// the flash command state, each command has to be unlocked first
+const (
+	flashReady      = iota // waiting for the first unlock byte
+	flashUnlocked1         // got 0xAA, waiting for 0x55
+	flashUnlocked2         // got 0x55, waiting for a command
+	flashProgram           // the next write programs a byte
+	flashEraseSetup        // got the erase command, waiting for the unlock again
+	flashErase1            // got 0xAA after the erase command
+	flashErase2            // got 0x55 after the erase command, waiting for what to erase
+)
+
+type MBC6 struct {
+	rom        banks // the rom in 8KiB banks
+	ram        banks // the ram in 4KiB banks
+	flash      []byte
+	hasBattery bool
+
+	ramEnabled    bool
+	ramBank       [2]byte // the ram bank for each half of 0xA000-0xBFFF
+	romBank       [2]byte // the rom or flash bank for each half of 0x4000-0x7FFF
+	useFlash      [2]bool // whether each half of 0x4000-0x7FFF maps the flash instead of the rom
+	flashEnabled  bool    // whether the flash can be mapped at all
+	flashWritable bool    // whether writes to the flash do anything
+	flashState    int     // where the flash is in a command, one of the flash states
+}
+
+// LoadFile implements MemoryBankController.
+// The ram is saved followed by the flash, saves from before the flash was saved just load the ram
+func (m *MBC6) LoadFile(file io.Reader) error {
+	if !m.hasBattery {
+		return nil
+	}
+
+	if err := m.ram.load(file); err != nil {
+		return err
+	}
+	if _, err := io.ReadFull(file, m.flash); err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	return nil
+}
+
+// SaveFile implements MemoryBankController.
+func (m *MBC6) SaveFile(file io.Writer) error {
+	if !m.hasBattery {
+		return nil
+	}
+
+	if err := m.ram.save(file); err != nil {
+		return err
+	}
+	_, err := file.Write(m.flash)
+	return err
+}
+
+// NewMBC6 create and return a new MBC6
+func NewMBC6(rom []byte, hasBattery bool, romSize int) *MBC6 {
+	mbc := new(MBC6)
+	mbc.hasBattery = hasBattery
+	mbc.rom = newROMBanks(rom, romSize, mbc6ROMBankSize)
+	mbc.ram = newRAMBanks(mbc6RAMSize, mbc6RAMBankSize)
+
+	mbc.flash = make([]byte, mbc6FlashSize)
+	for i := range mbc.flash { // erased flash reads as 1s
+		mbc.flash[i] = 0xFF
+	}
+
+	mbc.romBank = [2]byte{2, 3} // the same as a normal cart until they're switched
+	return mbc
+}
+
+// flashAddr get the flash address that addr in the given half of 0x4000-0x7FFF maps to
+func (m *MBC6) flashAddr(half int, addr uint16) int {
+	return (int(m.romBank[half]&0x7F)*mbc6ROMBankSize + int(addr&0x1FFF)) % mbc6FlashSize
+}
+
+// ReadByte read byte from addr
+func (m *MBC6) ReadByte(addr uint16) byte {
+	switch {
+	case addr <= 0x3FFF:
+		return m.rom.read(int(addr/mbc6ROMBankSize), addr)
+
+	case addr >= 0x4000 && addr <= 0x7FFF:
+		half := int(addr-0x4000) / mbc6ROMBankSize
+		if m.useFlash[half] && m.flashEnabled {
+			return m.flash[m.flashAddr(half, addr)]
+		}
+		return m.rom.read(int(m.romBank[half]), addr)
+
+	case addr >= 0xA000 && addr <= 0xBFFF:
+		if !m.ramEnabled {
+			return 0xFF
+		}
+		half := int(addr-0xA000) / mbc6RAMBankSize
+		return m.ram.read(int(m.ramBank[half]), addr)
+	}
+
+	return 0xFF
+}
+
+// WriteByte write data to addr
+// Returns whether the save changed, programming or erasing the flash changes it too
+func (m *MBC6) WriteByte(addr uint16, data byte) bool {
+	switch {
+	case addr <= 0x03FF:
+		m.ramEnabled = data&0x0F == 0x0A
+	case addr <= 0x07FF:
+		m.ramBank[0] = data & 0x07
+	case addr <= 0x0BFF:
+		m.ramBank[1] = data & 0x07
+	case addr <= 0x0FFF:
+		m.flashEnabled = data&0x01 != 0
+	case addr == 0x1000:
+		m.flashWritable = data&0x01 != 0
+
+	case addr >= 0x2000 && addr <= 0x27FF:
+		m.romBank[0] = data
+	case addr >= 0x2800 && addr <= 0x2FFF:
+		m.useFlash[0] = data == 0x08
+	case addr >= 0x3000 && addr <= 0x37FF:
+		m.romBank[1] = data
+	case addr >= 0x3800 && addr <= 0x3FFF:
+		m.useFlash[1] = data == 0x08
+
+	case addr >= 0x4000 && addr <= 0x7FFF:
+		half := int(addr-0x4000) / mbc6ROMBankSize
+		if m.useFlash[half] && m.flashEnabled {
+			return m.writeFlash(m.flashAddr(half, addr), data)
+		}
+
+	case addr >= 0xA000 && addr <= 0xBFFF:
+		if !m.ramEnabled {
+			return false
+		}
+		half := int(addr-0xA000) / mbc6RAMBankSize
+		return m.ram.write(int(m.ramBank[half]), addr, data)
+	}
+	return false
+}
+
+// writeFlash handle a write to the flash, it only changes when a command is run
+// Returns whether programming or erasing changed any of it
+func (m *MBC6) writeFlash(addr int, data byte) (changed bool) {
+	if data == 0xF0 { // reset works from anywhere
+		m.flashState = flashReady
+		return false
+	}
+
+	switch m.flashState {
+	case flashReady, flashEraseSetup:
+		if addr&0x7FFF == mbc6FlashUnlock1 && data == 0xAA {
+			m.flashState++
+			return false
+		}
+	case flashUnlocked1, flashErase1:
+		if addr&0x7FFF == mbc6FlashUnlock2 && data == 0x55 {
+			m.flashState++
+			return false
+		}
+	case flashUnlocked2:
+		switch data {
+		case 0xA0:
+			m.flashState = flashProgram
+			return false
+		case 0x80:
+			m.flashState = flashEraseSetup
+			return false
+		}
+	case flashProgram:
+		if m.flashWritable {
+			changed = m.flash[addr]&data != m.flash[addr]
+			m.flash[addr] &= data // programming can only clear bits
+		}
+	case flashErase2:
+		if !m.flashWritable {
+			break
+		}
+		switch data {
+		case 0x30: // erase the bank
+			start := addr &^ (mbc6ROMBankSize - 1)
+			changed = eraseFlash(m.flash[start : start+mbc6ROMBankSize])
+		case 0x10: // erase everything
+			changed = eraseFlash(m.flash)
+		}
+	}
+
+	m.flashState = flashReady
+	return changed
+}
+
+// eraseFlash set all of flash to 0xFF, returns whether any of it wasn't already
+func eraseFlash(flash []byte) (changed bool) {
+	for i := range flash {
+		changed = changed || flash[i] != 0xFF
+		flash[i] = 0xFF
+	}
+	return changed
+}
+
+// switchRAMBank set the ram bank for both halves of the ram area
+func (m *MBC6) switchRAMBank(bank int) {
+	m.ramBank = [2]byte{byte(bank) & 0x07, byte(bank) & 0x07}
+}
+
+// switchROMBank set the rom bank for both halves of the rom area, bank is in 16KiB banks
+func (m *MBC6) switchROMBank(bank int) {
+	m.romBank = [2]byte{byte(bank * 2), byte(bank*2 + 1)}
+}
+
+// HasBattery return whether or not MBC supports battery
+func (m *MBC6) HasBattery() bool {
+	return m.hasBattery
+}
@@ -0,0 +1,317 @@
+package cartridge
+
+import (
+	"encoding/binary"
+	"io"
+)
+
+///////////////
+// MBC7 Cart //
+///////////////
+//
+// Used by the tilt games (Kirby Tilt 'n' Tumble, Command Master). Instead of ram
+// it has an accelerometer and a 93LC56 EEPROM mapped into 0xA000-0xAFFF, picked
+// with bits 4-7 of the address. Both ram enables have to be set to use them.
+
+const (
+	mbc7TiltCentre = 0x81D0 // the accelerometer reading when the cart is flat
+	mbc7TiltPerG   = 0x70   // how much the reading changes for 1g of tilt
+	eepromWords    = 128    // the number of 16 bit words in the 93LC56
+)
+
+type MBC7 struct {
+	rom        banks // every rom bank including bank 0
+	romBank    byte  // the active rom bank (7 bits)
+	ramEnable1 bool  // set by writing 0x0A to 0x0000-0x1FFF
+	ramEnable2 bool  // set by writing 0x40 to 0x4000-0x5FFF
+	hasBattery bool
+
+	tiltX, tiltY uint16 // the latched accelerometer readings
+	latchReady   bool   // whether the latch was reset and can latch new readings
+	eeprom       eeprom93LC56
+
+	Tilt func() (x, y float64) // get the tilt in g, positive x is tilted right and positive y is tilted down
+}
+
+// eeprom93LC56 the serial EEPROM, the game bit bangs the chip select, clock and data lines
+type eeprom93LC56 struct {
+	words        [eepromWords]uint16
+	cs, clk, di  bool // the lines as the game last set them
+	do           bool // the data out line
+	state        int
+	shift        uint16 // the bits shifted in for the current command or value
+	bits         int    // how many bits have been shifted in
+	addr         byte   // the address for the current command
+	writeAll     bool   // whether the current write is a WRAL
+	writeEnabled bool   // whether the writes and erases work (set with EWEN)
+}
+
+// the eeprom states
+const (
+	eepromIdle    = iota // waiting for a start bit
+	eepromCommand        // shifting in the opcode and address
+	eepromReading        // shifting out the word
+	eepromWriting        // shifting in the word to write
+)
+
+// LoadFile implements MemoryBankController.
+func (m *MBC7) LoadFile(file io.Reader) error {
+	if !m.hasBattery {
+		return nil
+	}
+
+	return binary.Read(file, binary.LittleEndian, &m.eeprom.words)
+}
+
+// SaveFile implements MemoryBankController.
+func (m *MBC7) SaveFile(file io.Writer) error {
+	if !m.hasBattery {
+		return nil
+	}
+
+	return binary.Write(file, binary.LittleEndian, &m.eeprom.words)
+}
+
+// NewMBC7 create and return a new MBC7
+func NewMBC7(rom []byte, hasBattery bool, romSize int) *MBC7 {
+	mbc := new(MBC7)
+	mbc.hasBattery = hasBattery
+	mbc.rom = newROMBanks(rom, romSize, 0x4000)
+	mbc.romBank = 1
+	mbc.tiltX, mbc.tiltY = 0x8000, 0x8000
+
+	for i := range mbc.eeprom.words { // erased eeprom reads as 1s
+		mbc.eeprom.words[i] = 0xFFFF
+	}
+	mbc.eeprom.do = true
+	return mbc
+}
+
+// ReadByte read byte from addr
+func (m *MBC7) ReadByte(addr uint16) byte {
+	switch {
+	case addr <= 0x3FFF:
+		return m.rom.read(0, addr)
+
+	case addr >= 0x4000 && addr <= 0x7FFF:
+		return m.rom.read(int(m.romBank), addr-0x4000)
+
+	case addr >= 0xA000 && addr <= 0xAFFF:
+		if !m.ramEnable1 || !m.ramEnable2 {
+			return 0xFF
+		}
+
+		switch (addr >> 4) & 0x0F {
+		case 0x2:
+			return byte(m.tiltX)
+		case 0x3:
+			return byte(m.tiltX >> 8)
+		case 0x4:
+			return byte(m.tiltY)
+		case 0x5:
+			return byte(m.tiltY >> 8)
+		case 0x6:
+			return 0x00
+		case 0x8:
+			return m.eeprom.read()
+		}
+	}
+
+	return 0xFF
+}
+
+// WriteByte write data to addr
+// Returns whether the save changed
+func (m *MBC7) WriteByte(addr uint16, data byte) bool {
+	switch {
+	case addr <= 0x1FFF:
+		m.ramEnable1 = data == 0x0A
+		if !m.ramEnable1 {
+			m.ramEnable2 = false
+		}
+
+	case addr >= 0x2000 && addr <= 0x3FFF:
+		m.switchROMBank(int(data))
+
+	case addr >= 0x4000 && addr <= 0x5FFF:
+		m.ramEnable2 = m.ramEnable1 && data == 0x40
+
+	case addr >= 0xA000 && addr <= 0xAFFF:
+		if !m.ramEnable1 || !m.ramEnable2 {
+			return false
+		}
+
+		switch (addr >> 4) & 0x0F {
+		case 0x0: // reset the latch
+			if data == 0x55 {
+				m.tiltX, m.tiltY = 0x8000, 0x8000
+				m.latchReady = true
+			}
+		case 0x1: // latch the accelerometer
+			if data == 0xAA && m.latchReady {
+				m.latchTilt()
+				m.latchReady = false
+			}
+		case 0x8:
+			return m.eeprom.write(data)
+		}
+	}
+	return false
+}
+
+// latchTilt latch the tilt from Tilt, the cart is flat if there's nothing to get it from
+func (m *MBC7) latchTilt() {
+	var x, y float64
+	if m.Tilt != nil {
+		x, y = m.Tilt()
+	}
+
+	m.tiltX = uint16(mbc7TiltCentre + int(x*mbc7TiltPerG))
+	m.tiltY = uint16(mbc7TiltCentre + int(y*mbc7TiltPerG))
+}
+
+// switchROMBank switch to the rom bank in the lower 7 bits of bank
+func (m *MBC7) switchROMBank(bank int) {
+	m.romBank = byte(bank & 0x7F)
+}
+
+// switchRAMBank does nothing, the MBC7 has no ram banks
+func (m *MBC7) switchRAMBank(bank int) {
+
+}
+
+// HasBattery return whether or not MBC supports battery
+func (m *MBC7) HasBattery() bool {
+	return m.hasBattery
+}
+
+// read read the eeprom lines, bit 0 is the data out and the rest are what the game last wrote
+func (e *eeprom93LC56) read() byte {
+	var value byte
+	if e.cs {
+		value |= 0x80
+	}
+	if e.clk {
+		value |= 0x40
+	}
+	if e.di {
+		value |= 0x02
+	}
+	if e.do {
+		value |= 0x01
+	}
+	return value
+}
+
+// write set the eeprom lines, bits are shifted in and out on the rising edge of the clock
+// Returns whether a word was changed by a write or erase
+func (e *eeprom93LC56) write(data byte) bool {
+	cs, clk, di := data&0x80 != 0, data&0x40 != 0, data&0x02 != 0
+	rising := clk && !e.clk
+	e.cs, e.clk, e.di = cs, clk, di
+
+	if !cs { // deselecting cancels whatever was going on
+		e.state = eepromIdle
+		e.do = true
+		return false
+	}
+	if !rising {
+		return false
+	}
+
+	bit := uint16(0)
+	if di {
+		bit = 1
+	}
+
+	switch e.state {
+	case eepromIdle:
+		if di { // leading 0s are ignored until the start bit
+			e.state = eepromCommand
+			e.shift, e.bits = 0, 0
+		}
+
+	case eepromCommand: // 2 bit opcode then 8 bit address (only 7 used)
+		e.shift = e.shift<<1 | bit
+		e.bits++
+		if e.bits == 10 {
+			return e.runCommand(byte(e.shift>>8)&0x03, byte(e.shift))
+		}
+
+	case eepromReading: // the word is shifted out msb first after a dummy 0
+		e.do = e.shift&0x8000 != 0
+		e.shift <<= 1
+		e.bits++
+		if e.bits == 16 { // keep going with the next word
+			e.addr = (e.addr + 1) % eepromWords
+			e.shift, e.bits = e.words[e.addr], 0
+		}
+
+	case eepromWriting:
+		e.shift = e.shift<<1 | bit
+		e.bits++
+		if e.bits == 16 {
+			e.state = eepromIdle
+			e.do = true
+			if !e.writeEnabled {
+				return false
+			}
+			if e.writeAll {
+				return e.fill(e.shift)
+			}
+			changed := e.words[e.addr] != e.shift
+			e.words[e.addr] = e.shift
+			return changed
+		}
+	}
+	return false
+}
+
+// runCommand run the command once its opcode and address have been shifted in
+// Returns whether it erased anything that wasn't already erased
+func (e *eeprom93LC56) runCommand(opcode, addr byte) bool {
+	e.addr = addr % eepromWords
+	e.shift, e.bits = 0, 0
+	e.state = eepromIdle
+	e.do = true
+
+	switch opcode {
+	case 0x2: // READ
+		e.state = eepromReading
+		e.shift = e.words[e.addr]
+		e.do = false
+	case 0x1: // WRITE
+		e.state = eepromWriting
+		e.writeAll = false
+	case 0x3: // ERASE
+		if e.writeEnabled {
+			changed := e.words[e.addr] != 0xFFFF
+			e.words[e.addr] = 0xFFFF
+			return changed
+		}
+	case 0x0: // the top 2 address bits pick the command
+		switch addr >> 6 {
+		case 0x0: // EWDS
+			e.writeEnabled = false
+		case 0x1: // WRAL
+			e.state = eepromWriting
+			e.writeAll = true
+		case 0x2: // ERAL
+			if e.writeEnabled {
+				return e.fill(0xFFFF)
+			}
+		case 0x3: // EWEN
+			e.writeEnabled = true
+		}
+	}
+	return false
+}
+
+// fill set every word to value, returns whether any of them changed
+func (e *eeprom93LC56) fill(value uint16) (changed bool) {
+	for i := range e.words {
+		changed = changed || e.words[i] != value
+		e.words[i] = value
+	}
+	return changed
+}
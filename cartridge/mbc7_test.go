@@ -0,0 +1,59 @@
+package cartridge
+
+import "testing"
+
+// clockEEPROM clock bits (msb first) into the MBC7 eeprom and return what was on DO after each clock
+func clockEEPROM(mbc *MBC7, value uint32, bits int) uint32 {
+	var out uint32
+	for i := bits - 1; i >= 0; i-- {
+		di := byte(value>>i&1) << 1
+		mbc.WriteByte(0xA080, 0x80|di)
+		mbc.WriteByte(0xA080, 0xC0|di)
+		out = out<<1 | uint32(mbc.ReadByte(0xA080)&0x01)
+	}
+	return out
+}
+
+func TestMBC7EEPROM(t *testing.T) {
+	mbc := NewMBC7(bankedROM(4), true, 4*0x4000)
+	mbc.WriteByte(0x0000, 0x0A)
+	mbc.WriteByte(0x4000, 0x40)
+
+	deselect := func() { mbc.WriteByte(0xA080, 0x00) }
+
+	clockEEPROM(mbc, 0b1_01_00000101<<16|0x1234, 27) // WRITE before EWEN does nothing
+	deselect()
+	clockEEPROM(mbc, 0b1_00_11000000, 11) // EWEN
+	deselect()
+	clockEEPROM(mbc, 0b1_01_00000101<<16|0xBEEF, 27) // WRITE 0xBEEF to word 5
+	deselect()
+
+	if got := clockEEPROM(mbc, 0b1_10_00000101<<16, 27) & 0x1FFFF; got != 0xBEEF {
+		t.Errorf("Read 0x%05X from word 5, want a dummy 0 then 0xBEEF", got)
+	}
+	deselect()
+
+	if got := mbc.eeprom.words[6]; got != 0xFFFF {
+		t.Errorf("Word 6 is 0x%04X, want it left erased", got)
+	}
+}
+
+func TestMBC7Tilt(t *testing.T) {
+	mbc := NewMBC7(bankedROM(4), true, 4*0x4000)
+	mbc.Tilt = func() (float64, float64) { return 1, -0.5 }
+	mbc.WriteByte(0x0000, 0x0A)
+	mbc.WriteByte(0x4000, 0x40)
+
+	mbc.WriteByte(0xA010, 0xAA) // latching without resetting first does nothing
+	if x := uint16(mbc.ReadByte(0xA030))<<8 | uint16(mbc.ReadByte(0xA020)); x != 0x8000 {
+		t.Errorf("Got x 0x%04X before latching, want 0x8000", x)
+	}
+
+	mbc.WriteByte(0xA000, 0x55)
+	mbc.WriteByte(0xA010, 0xAA)
+	x := uint16(mbc.ReadByte(0xA030))<<8 | uint16(mbc.ReadByte(0xA020))
+	y := uint16(mbc.ReadByte(0xA050))<<8 | uint16(mbc.ReadByte(0xA040))
+	if x != mbc7TiltCentre+mbc7TiltPerG || y != mbc7TiltCentre-mbc7TiltPerG/2 {
+		t.Errorf("Got tilt 0x%04X, 0x%04X", x, y)
+	}
+}
@@ -0,0 +1,178 @@
+package cartridge
+
+import "io"
+
+////////////////
+// MMM01 Cart //
+////////////////
+//
+// Used by multi game carts, it starts up "unmapped" with the last 32KiB of the
+// rom (the menu) at 0x0000-0x7FFF. The menu sets the outer bank bits for the
+// picked game and then sets the map enable bit, after which the cart acts like
+// a MBC1 within that game and the outer bits can't be changed until reset.
+//
+// NOTE - dumps with the menu at the start of the file instead of the end won't work
+
+type MMM01Mapper struct {
+	rom banks // every rom bank including bank 0
+	ram banks // the ram banks (if any)
+
+	mapped     bool // whether the map enable bit has been set, locks the outer bank bits
+	ramEnabled bool
+	hasRAM     bool
+	hasBattery bool
+
+	romLow   byte // rom bank bits 0-4 (MBC1 bank1 register)
+	romMid   byte // rom bank bits 5-6
+	romHigh  byte // rom bank bits 7-8
+	romMask  byte // which of rom bank bits 1-4 are locked once mapped (bits 1-4 set)
+	ramLow   byte // ram bank bits 0-1 (MBC1 bank2 register)
+	ramHigh  byte // ram bank bits 2-3
+	ramMask  byte // which of ram bank bits 0-1 are locked once mapped
+	mode     byte // the MBC1 banking mode
+	modeLock bool // whether the banking mode is locked once mapped
+}
+
+// LoadFile implements MemoryBankController.
+func (m *MMM01Mapper) LoadFile(file io.Reader) error {
+	if !m.hasBattery || !m.hasRAM {
+		return nil
+	}
+	return m.ram.load(file)
+}
+
+// SaveFile implements MemoryBankController.
+func (m *MMM01Mapper) SaveFile(file io.Writer) error {
+	if !m.hasBattery || !m.hasRAM {
+		return nil
+	}
+
+	return m.ram.save(file)
+}
+
+// NewMMM01 create and return a new MMM01
+func NewMMM01(rom []byte, hasBattery bool, ramSize, romSize int) *MMM01Mapper {
+	mbc := new(MMM01Mapper)
+	mbc.hasBattery = hasBattery
+
+	if ramSize > 0 {
+		mbc.hasRAM = true
+		mbc.ram = newRAMBanks(ramSize, 0x2000)
+	}
+
+	mbc.rom = newROMBanks(rom, romSize, 0x4000)
+	mbc.romLow = 1
+	return mbc
+}
+
+// romBank the rom bank mapped to 0x4000-0x7FFF if high, otherwise 0x0000-0x3FFF
+func (m *MMM01Mapper) romBank(high bool) int {
+	if !m.mapped { // the menu is always in the last 2 banks
+		if high {
+			return m.rom.count() - 1
+		}
+		return m.rom.count() - 2
+	}
+
+	outer := int(m.romHigh)<<7 | int(m.romMid)<<5
+	locked := int(m.romLow & m.romMask) // locked bits are part of the games base bank
+
+	if !high {
+		return outer | locked
+	}
+
+	free := int(m.romLow &^ m.romMask & 0x1F)
+	if free == 0 { // like MBC1 only the bits the game can change are checked for 0
+		free = 1
+	}
+	return outer | locked | free
+}
+
+// ramBank the active ram bank
+func (m *MMM01Mapper) ramBank() int {
+	bank := int(m.ramHigh)<<2 | int(m.ramLow&m.ramMask)
+	if m.mode == 1 { // the bits the game can change only work in mode 1 like MBC1
+		bank |= int(m.ramLow &^ m.ramMask)
+	}
+	return bank
+}
+
+// ReadByte read byte from addr
+func (m *MMM01Mapper) ReadByte(addr uint16) byte {
+	switch {
+	case addr <= 0x3FFF:
+		return m.rom.read(m.romBank(false), addr)
+
+	case addr >= 0x4000 && addr <= 0x7FFF:
+		return m.rom.read(m.romBank(true), addr-0x4000)
+
+	case addr >= 0xA000 && addr <= 0xBFFF:
+		if m.hasRAM && m.ramEnabled {
+			return m.ram.read(m.ramBank(), addr-0xA000)
+		}
+	}
+
+	return 0xFF
+}
+
+// WriteByte write data to addr, most of the bits only work before the cart is mapped
+// Returns whether the save changed
+func (m *MMM01Mapper) WriteByte(addr uint16, data byte) bool {
+	switch {
+	case addr <= 0x1FFF: // ram enable, ram mask and map enable
+		m.ramEnabled = data&0x0F == 0x0A
+		if !m.mapped {
+			m.ramMask = (data >> 4) & 0x03
+			m.mapped = data&0x40 != 0
+		}
+
+	case addr >= 0x2000 && addr <= 0x3FFF: // rom bank low and mid
+		m.switchROMBank(int(data))
+
+	case addr >= 0x4000 && addr <= 0x5FFF: // ram bank and rom bank high
+		m.switchRAMBank(int(data))
+
+	case addr >= 0x6000 && addr <= 0x7FFF: // banking mode and rom mask
+		if !m.mapped {
+			m.romMask = (data >> 1) & 0x1E
+		}
+		if !m.mapped || !m.modeLock {
+			m.mode = data & 0x01
+		}
+
+	case addr >= 0xA000 && addr <= 0xBFFF:
+		if m.hasRAM && m.ramEnabled {
+			return m.ram.write(m.ramBank(), addr-0xA000, data)
+		}
+	}
+	return false
+}
+
+// switchROMBank set the rom bank low bits that aren't locked (and the mid bits before mapping)
+func (m *MMM01Mapper) switchROMBank(bank int) {
+	if m.mapped {
+		m.romLow = m.romLow&m.romMask | byte(bank)&0x1F&^m.romMask
+		return
+	}
+
+	m.romLow = byte(bank) & 0x1F
+	m.romMid = byte(bank>>5) & 0x03
+}
+
+// switchRAMBank set the ram bank low bits that aren't locked (and the outer bits before mapping)
+func (m *MMM01Mapper) switchRAMBank(bank int) {
+	if m.mapped {
+		m.ramLow = m.ramLow&m.ramMask | byte(bank)&0x03&^m.ramMask
+		return
+	}
+
+	m.ramLow = byte(bank) & 0x03
+	m.ramHigh = byte(bank>>2) & 0x03
+	m.romHigh = byte(bank>>4) & 0x03
+	m.modeLock = bank&0x40 != 0
+}
+
+// HasBattery return whether or not MBC supports battery
+func (m *MMM01Mapper) HasBattery() bool {
+	return m.hasBattery
+}
@@ -0,0 +1,152 @@
+package cartridge
+
+import "io"
+
+///////////////////////
+// Bandai TAMA5 Cart //
+///////////////////////
+//
+// Only used by Game de Hakken!! Tamagotchi 3. Everything goes through two
+// addresses, the register number is written to 0xA001 then a nibble is written to
+// or read from 0xA000. The rom bank is set through registers 0 and 1 and the 32
+// bytes of ram are read and written with commands.
+//
+// The TC8521AM clock on the cart isn't emulated, clock commands do nothing.
+
+// the TAMA5 registers
+const (
+	tama5ROMLow    = 0x0 // rom bank bits 0-3
+	tama5ROMHigh   = 0x1 // rom bank bit 4
+	tama5DataLow   = 0x4 // the lower nibble of the value to write
+	tama5DataHigh  = 0x5 // the upper nibble of the value to write
+	tama5Command   = 0x6 // bit 0 is address bit 4, bits 1-3 are the command
+	tama5AddrLow   = 0x7 // address bits 0-3, writing runs the command
+	tama5Enable    = 0xA // written before the game starts using the cart
+	tama5ResultLow = 0xC // the lower nibble of the last read
+	tama5ResultHi  = 0xD // the upper nibble of the last read
+
+	tama5WriteRAM = 0x0
+	tama5ReadRAM  = 0x1
+	tama5RAMSize  = 0x20
+)
+
+type TAMA5 struct {
+	rom        banks
+	romBank    byte
+	ram        [tama5RAMSize]byte
+	reg        byte // the selected register
+	data       byte // the value for the next write command
+	command    byte // the value of the command register
+	result     byte // the value from the last read command
+	hasBattery bool
+}
+
+// LoadFile implements MemoryBankController.
+func (m *TAMA5) LoadFile(file io.Reader) error {
+	if !m.hasBattery {
+		return nil
+	}
+
+	_, err := io.ReadFull(file, m.ram[:])
+	return err
+}
+
+// SaveFile implements MemoryBankController.
+func (m *TAMA5) SaveFile(file io.Writer) error {
+	if !m.hasBattery {
+		return nil
+	}
+
+	_, err := file.Write(m.ram[:])
+	return err
+}
+
+// NewTAMA5 create and return a new TAMA5, it always has a battery
+func NewTAMA5(rom []byte, romSize int) *TAMA5 {
+	mbc := new(TAMA5)
+	mbc.hasBattery = true
+	mbc.rom = newROMBanks(rom, romSize, 0x4000)
+	mbc.romBank = 1
+	return mbc
+}
+
+// ReadByte read byte from addr
+func (m *TAMA5) ReadByte(addr uint16) byte {
+	switch {
+	case addr <= 0x3FFF:
+		return m.rom.read(0, addr)
+
+	case addr >= 0x4000 && addr <= 0x7FFF:
+		return m.rom.read(int(m.romBank), addr-0x4000)
+
+	case addr == 0xA000:
+		switch m.reg {
+		case tama5ResultLow:
+			return 0xF0 | m.result&0x0F
+		case tama5ResultHi:
+			return 0xF0 | m.result>>4
+		}
+		return 0xF0
+
+	case addr == 0xA001:
+		return 0xF1 // always ready
+	}
+
+	return 0xFF
+}
+
+// WriteByte write data to addr
+// Returns whether the save changed
+func (m *TAMA5) WriteByte(addr uint16, data byte) bool {
+	switch addr {
+	case 0xA001:
+		m.reg = data & 0x0F
+
+	case 0xA000:
+		data &= 0x0F
+		switch m.reg {
+		case tama5ROMLow:
+			m.switchROMBank(int(m.romBank&0x10 | data))
+		case tama5ROMHigh:
+			m.switchROMBank(int(m.romBank&0x0F | (data&0x01)<<4))
+		case tama5DataLow:
+			m.data = m.data&0xF0 | data
+		case tama5DataHigh:
+			m.data = m.data&0x0F | data<<4
+		case tama5Command:
+			m.command = data
+		case tama5AddrLow:
+			return m.runCommand((m.command&0x01)<<4 | data)
+		}
+	}
+	return false
+}
+
+// runCommand run the command in the command register on addr
+// Returns whether it changed the ram
+func (m *TAMA5) runCommand(addr byte) bool {
+	switch m.command >> 1 {
+	case tama5WriteRAM:
+		changed := m.ram[addr] != m.data
+		m.ram[addr] = m.data
+		return changed
+	case tama5ReadRAM:
+		m.result = m.ram[addr]
+	}
+	return false
+}
+
+// switchROMBank switch to the rom bank in the lower 5 bits of bank
+func (m *TAMA5) switchROMBank(bank int) {
+	m.romBank = byte(bank & 0x1F)
+}
+
+// switchRAMBank does nothing, the TAMA5 ram isn't banked
+func (m *TAMA5) switchRAMBank(bank int) {
+
+}
+
+// HasBattery return whether or not MBC supports battery
+func (m *TAMA5) HasBattery() bool {
+	return m.hasBattery
+}
@@ -0,0 +1,307 @@
+// package config handles loading the user configuration file
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/TheOrnyx/dmg-go/joypad"
+)
+
+const fileName = "config.json"
+
+// ButtonNames the names used in the config for each of the joypad buttons
+var ButtonNames = map[string]int{
+	"a":      joypad.ButtonA,
+	"b":      joypad.ButtonB,
+	"select": joypad.ButtonSel,
+	"start":  joypad.ButtonStart,
+	"right":  joypad.DpadRight,
+	"left":   joypad.DpadLeft,
+	"up":     joypad.DpadUp,
+	"down":   joypad.DpadDown,
+}
+
+// TiltNames the directions used in the config for tilting carts with an accelerometer
+var TiltNames = []string{"up", "down", "left", "right"}
+
+// Config the user configuration
+// Keys are given by their SDL scancode name (e.g "Z", "Right", "Escape", "F12")
+type Config struct {
+	Buttons     map[string][]string         `json:"buttons"`     // joypad button name -> keys bound to it
+	Hotkeys     map[string][]string         `json:"hotkeys"`     // hotkey name -> keys bound to it
+	Tilt        map[string][]string         `json:"tilt"`        // tilt direction -> keys bound to it, for MBC7 carts
+	Controller  ControllerConfig            `json:"controller"`  // the bindings used for every game controller
+	Controllers map[string]ControllerConfig `json:"controllers"` // per controller GUID changes on top of Controller
+	Palette     string                      `json:"palette"`     // the name of the palette used for roms without a saved one
+	Palettes    []PaletteConfig             `json:"palettes"`    // extra palettes on top of the built in ones
+
+	dir string // the directory the config was loaded from
+}
+
+// ControllerConfig the bindings for a game controller
+// Buttons are given by their SDL game controller name (e.g "a", "back", "dpup")
+type ControllerConfig struct {
+	Buttons   map[string][]string `json:"buttons,omitempty"`    // joypad button name -> controller buttons bound to it
+	Stick     string              `json:"stick,omitempty"`      // the analog stick that moves the dpad ("left", "right" or "none")
+	Deadzone  int                 `json:"deadzone,omitempty"`   // how far the stick has to move before it counts (0-32767)
+	TiltStick string              `json:"tilt_stick,omitempty"` // the analog stick that tilts MBC7 carts ("left", "right" or "none")
+}
+
+// Default return the default configuration
+func Default() *Config {
+	return &Config{
+		Buttons: map[string][]string{
+			"a":      {"Z"},
+			"b":      {"X"},
+			"select": {"S"},
+			"start":  {"A"},
+			"right":  {"Right"},
+			"left":   {"Left"},
+			"up":     {"Up"},
+			"down":   {"Down"},
+		},
+		Hotkeys: map[string][]string{
+			"quit":         {"Escape"},
+			"next-palette": {"R"},
+			"prev-palette": {"E"},
+			"screenshot":   {"F12"},
+			"fullscreen":   {"F11"},
+		},
+		Tilt: map[string][]string{
+			"up":    {"I"},
+			"down":  {"K"},
+			"left":  {"J"},
+			"right": {"L"},
+		},
+		Controller: ControllerConfig{
+			Buttons: map[string][]string{
+				"a":      {"b"}, // SDL uses the xbox layout so this matches the gameboy's button positions
+				"b":      {"a"},
+				"select": {"back"},
+				"start":  {"start"},
+				"right":  {"dpright"},
+				"left":   {"dpleft"},
+				"up":     {"dpup"},
+				"down":   {"dpdown"},
+			},
+			Stick:     "left",
+			Deadzone:  8000,
+			TiltStick: "right",
+		},
+		Controllers: map[string]ControllerConfig{},
+		Palette:     "green",
+		Palettes:    []PaletteConfig{},
+	}
+}
+
+// ControllerFor get the bindings for the controller with the given GUID,
+// anything its own entry doesn't set comes from the shared controller bindings
+func (c *Config) ControllerFor(guid string) ControllerConfig {
+	ctrl := ControllerConfig{
+		Buttons:   make(map[string][]string),
+		Stick:     c.Controller.Stick,
+		Deadzone:  c.Controller.Deadzone,
+		TiltStick: c.Controller.TiltStick,
+	}
+	for name, buttons := range c.Controller.Buttons {
+		ctrl.Buttons[name] = buttons
+	}
+
+	override, found := c.Controllers[guid]
+	if !found {
+		return ctrl
+	}
+
+	for name, buttons := range override.Buttons {
+		ctrl.Buttons[name] = buttons
+	}
+	if override.Stick != "" {
+		ctrl.Stick = override.Stick
+	}
+	if override.Deadzone != 0 {
+		ctrl.Deadzone = override.Deadzone
+	}
+	if override.TiltStick != "" {
+		ctrl.TiltStick = override.TiltStick
+	}
+
+	return ctrl
+}
+
+// DefaultPath get the default config file location using
+// XDG_CONFIG_HOME or the OS equivalent
+func DefaultPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "dmg-go", fileName), nil
+}
+
+// Load load the config at path on top of the defaults
+// If the file doesn't exist the defaults are returned
+func Load(path string) (*Config, error) {
+	cfg := Default()
+	cfg.dir = filepath.Dir(path)
+
+	file, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Failed to open config: %v", err)
+	}
+	defer file.Close()
+
+	// the key bindings are decoded on their own so clashes can be checked
+	// against just the file, everything else keeps its default if it isn't set
+	defaults := []map[string][]string{cfg.Buttons, cfg.Hotkeys, cfg.Tilt}
+	cfg.Buttons, cfg.Hotkeys, cfg.Tilt = nil, nil, nil
+	if err := json.NewDecoder(file).Decode(cfg); err != nil {
+		return nil, fmt.Errorf("Failed to parse config %s: %v", path, err)
+	}
+
+	// "palette" is the old name for "next-palette", so it replaces the default keys
+	if keys, found := cfg.Hotkeys["palette"]; found {
+		cfg.Hotkeys["next-palette"] = keys
+		delete(cfg.Hotkeys, "palette")
+	}
+
+	if err := cfg.validate(); err != nil {
+		return nil, fmt.Errorf("Invalid config %s: %v", path, err)
+	}
+
+	cfg.mergeKeys(defaults[0], defaults[1], defaults[2])
+	return cfg, nil
+}
+
+// mergeKeys fill in the key bindings the config doesn't set from the defaults,
+// leaving out any default key the config binds to something else
+func (c *Config) mergeKeys(buttons, hotkeys, tilt map[string][]string) {
+	bound := make(map[string]bool)
+	for _, bindings := range []map[string][]string{c.Buttons, c.Hotkeys, c.Tilt} {
+		for _, keys := range bindings {
+			for _, key := range keys {
+				bound[strings.ToLower(key)] = true
+			}
+		}
+	}
+
+	merge := func(bindings, defaults map[string][]string) map[string][]string {
+		if bindings == nil {
+			bindings = make(map[string][]string)
+		}
+		for name, keys := range defaults {
+			if _, found := bindings[name]; found {
+				continue
+			}
+			bindings[name] = slices.DeleteFunc(slices.Clone(keys), func(key string) bool {
+				return bound[strings.ToLower(key)]
+			})
+		}
+		return bindings
+	}
+
+	c.Buttons = merge(c.Buttons, buttons)
+	c.Hotkeys = merge(c.Hotkeys, hotkeys)
+	c.Tilt = merge(c.Tilt, tilt)
+}
+
+// validate check all the joypad button names and controller settings are valid
+func (c *Config) validate() error {
+	buttonMaps := []map[string][]string{c.Buttons, c.Controller.Buttons}
+	controllers := []ControllerConfig{c.Controller}
+	for _, ctrl := range c.Controllers {
+		buttonMaps = append(buttonMaps, ctrl.Buttons)
+		controllers = append(controllers, ctrl)
+	}
+
+	for _, buttons := range buttonMaps {
+		for name := range buttons {
+			if _, found := ButtonNames[name]; !found {
+				return fmt.Errorf("Unknown button %q", name)
+			}
+		}
+	}
+
+	for name := range c.Tilt {
+		if !slices.Contains(TiltNames, name) {
+			return fmt.Errorf("Unknown tilt direction %q", name)
+		}
+	}
+
+	if err := c.checkDuplicateKeys(); err != nil {
+		return err
+	}
+
+	for _, ctrl := range controllers {
+		for _, stick := range []string{ctrl.Stick, ctrl.TiltStick} {
+			switch stick {
+			case "", "left", "right", "none":
+			default:
+				return fmt.Errorf("Unknown stick %q, should be left, right or none", stick)
+			}
+		}
+
+		if ctrl.Deadzone < 0 || ctrl.Deadzone > 32767 {
+			return fmt.Errorf("Deadzone %v out of range 0-32767", ctrl.Deadzone)
+		}
+	}
+
+	return nil
+}
+
+// checkDuplicateKeys check no key is bound to more than one button, hotkey or
+// tilt direction in the config file, before the defaults are merged in
+// Key names are compared ignoring case like SDL does
+func (c *Config) checkDuplicateKeys() error {
+	groups := []struct {
+		kind     string
+		bindings map[string][]string
+	}{
+		{"button", c.Buttons},
+		{"hotkey", c.Hotkeys},
+		{"tilt", c.Tilt},
+	}
+
+	boundTo := make(map[string]string) // key -> what it's bound to
+	for _, group := range groups {
+		names := make([]string, 0, len(group.bindings))
+		for name := range group.bindings {
+			names = append(names, name)
+		}
+		slices.Sort(names) // so the error is the same every time
+
+		for _, name := range names {
+			for _, key := range group.bindings[name] {
+				binding := fmt.Sprintf("%s %q", group.kind, name)
+				if other, found := boundTo[strings.ToLower(key)]; found {
+					return fmt.Errorf("Key %q is bound to both %s and %s", key, other, binding)
+				}
+				boundTo[strings.ToLower(key)] = binding
+			}
+		}
+	}
+
+	return nil
+}
+
+// Write write the config as indented json to w
+func (c *Config) Write(w io.Writer) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "%s\n", data)
+	return err
+}
@@ -0,0 +1,185 @@
+package config
+
+import (
+	"image/color"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/TheOrnyx/dmg-go/ppu"
+)
+
+// TestLoadMergesDefaults check a partial config only overrides what it sets
+func TestLoadMergesDefaults(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	data := `{"buttons": {"a": ["Z", "N"]}, "hotkeys": {"next-palette": []}}`
+	if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	if !slices.Equal(cfg.Buttons["a"], []string{"Z", "N"}) {
+		t.Errorf("a button = %v, want [Z N]", cfg.Buttons["a"])
+	}
+	if !slices.Equal(cfg.Buttons["b"], Default().Buttons["b"]) {
+		t.Errorf("b button = %v, want the default", cfg.Buttons["b"])
+	}
+	if len(cfg.Hotkeys["next-palette"]) != 0 {
+		t.Errorf("next-palette hotkey = %v, want it unbound", cfg.Hotkeys["next-palette"])
+	}
+}
+
+// TestLoadMissingFile check a missing config gives the defaults
+func TestLoadMissingFile(t *testing.T) {
+	cfg, err := Load(filepath.Join(t.TempDir(), "missing.json"))
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	if !slices.Equal(cfg.Buttons["start"], []string{"A"}) {
+		t.Errorf("start button = %v, want the default", cfg.Buttons["start"])
+	}
+}
+
+// TestLoadUnknownButton check unknown buttons are rejected
+func TestLoadUnknownButton(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"buttons": {"turbo": ["T"]}}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Load(path); err == nil {
+		t.Error("Expected an error for an unknown button")
+	}
+}
+
+// TestLoadOldPaletteHotkey check the old "palette" hotkey name replaces the
+// default next-palette keys instead of clashing with them
+func TestLoadOldPaletteHotkey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"hotkeys": {"palette": ["R"]}}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	if !slices.Equal(cfg.Hotkeys["next-palette"], []string{"R"}) {
+		t.Errorf("next-palette hotkey = %v, want [R]", cfg.Hotkeys["next-palette"])
+	}
+}
+
+// TestLoadDuplicateKey check a key bound to two things in the config file is rejected
+func TestLoadDuplicateKey(t *testing.T) {
+	tests := []string{
+		`{"buttons": {"a": ["Z"], "b": ["z"]}}`,
+		`{"buttons": {"a": ["Q"]}, "hotkeys": {"screenshot": ["q"]}}`,
+		`{"hotkeys": {"quit": ["P"]}, "tilt": {"up": ["P"]}}`,
+	}
+
+	for _, data := range tests {
+		path := filepath.Join(t.TempDir(), "config.json")
+		if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := Load(path); err == nil {
+			t.Errorf("Expected an error for the duplicate key in %s", data)
+		}
+	}
+}
+
+// TestLoadRebindDefaultKey check binding a key the defaults use takes it away
+// from the default binding instead of being rejected
+func TestLoadRebindDefaultKey(t *testing.T) {
+	tests := []struct {
+		data    string
+		key     string
+		boundTo []string // the bindings that should have key, as group/name
+	}{
+		{`{"buttons": {"a": ["J"], "b": ["K"]}}`, "K", []string{"buttons/b"}},
+		{`{"buttons": {"a": ["r"]}}`, "R", []string{"buttons/a"}},
+		{`{"hotkeys": {"screenshot": ["Z"]}}`, "Z", []string{"hotkeys/screenshot"}},
+		{`{"tilt": {"up": ["Up"]}}`, "Up", []string{"tilt/up"}},
+		{`{"buttons": {"start": ["F11"]}}`, "F11", []string{"buttons/start"}},
+	}
+
+	for _, test := range tests {
+		path := filepath.Join(t.TempDir(), "config.json")
+		if err := os.WriteFile(path, []byte(test.data), 0600); err != nil {
+			t.Fatal(err)
+		}
+
+		cfg, err := Load(path)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", test.data, err)
+			continue
+		}
+
+		var boundTo []string
+		groups := map[string]map[string][]string{"buttons": cfg.Buttons, "hotkeys": cfg.Hotkeys, "tilt": cfg.Tilt}
+		for group, bindings := range groups {
+			for name, keys := range bindings {
+				if slices.ContainsFunc(keys, func(key string) bool { return strings.EqualFold(key, test.key) }) {
+					boundTo = append(boundTo, group+"/"+name)
+				}
+			}
+		}
+		if !slices.Equal(boundTo, test.boundTo) {
+			t.Errorf("%s: %q is bound to %v, want %v", test.data, test.key, boundTo, test.boundTo)
+		}
+	}
+}
+
+// TestLoadPalettes check palettes load from both the config colours and
+// from JASC files relative to the config
+func TestLoadPalettes(t *testing.T) {
+	dir := t.TempDir()
+	jasc := "JASC-PAL\n0100\n4\n224 248 208\n136 192 112\n52 104 86\n8 24 32\n"
+	if err := os.WriteFile(filepath.Join(dir, "mint.pal"), []byte(jasc), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	data := `{"palettes": [
+		{"file": "mint.pal"},
+		{"name": "split", "bg": ["#FFFFFF", "#AAAAAA", "#555555", "#000000"], "obp1": ["FF0000", "AA0000", "550000", "000000"]}
+	]}`
+	path := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	palettes, err := cfg.LoadPalettes()
+	if err != nil {
+		t.Fatalf("Failed to load palettes: %v", err)
+	}
+	if len(palettes) != 2 {
+		t.Fatalf("Got %d palettes, want 2", len(palettes))
+	}
+
+	mint := palettes[0]
+	if mint.Name != "mint" || mint.Colors[ppu.PaletteOBP1][3] != (color.RGBA{R: 8, G: 24, B: 32, A: 0xFF}) {
+		t.Errorf("mint palette = %+v", mint)
+	}
+
+	split := palettes[1]
+	if split.Colors[ppu.PaletteOBP0] != split.Colors[ppu.PaletteBG] {
+		t.Errorf("split OBP0 = %v, want the BG colours", split.Colors[ppu.PaletteOBP0])
+	}
+	if split.Colors[ppu.PaletteOBP1][0] != (color.RGBA{R: 0xFF, A: 0xFF}) {
+		t.Errorf("split OBP1 = %v, want the red colours", split.Colors[ppu.PaletteOBP1])
+	}
+}
@@ -0,0 +1,205 @@
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"image/color"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/TheOrnyx/dmg-go/ppu"
+)
+
+// PaletteConfig a palette defined in the config, either by its colours or by a
+// .pal/.hex file. Colours are given as hex strings (e.g "#9BBC0F") from lightest to darkest
+type PaletteConfig struct {
+	Name string   `json:"name,omitempty"` // defaults to the file name when loading from a file
+	File string   `json:"file,omitempty"` // a .pal or .hex file to load, relative paths are from the config directory
+	BG   []string `json:"bg,omitempty"`   // the background and window colours
+	OBP0 []string `json:"obp0,omitempty"` // the colours for objects using OBP0, defaults to BG
+	OBP1 []string `json:"obp1,omitempty"` // the colours for objects using OBP1, defaults to OBP0
+}
+
+// LoadPalettes build the palettes defined in the config
+func (c *Config) LoadPalettes() ([]ppu.Palette, error) {
+	var palettes []ppu.Palette
+
+	for i, palCfg := range c.Palettes {
+		palette, err := palCfg.load(c.dir)
+		if err != nil {
+			return nil, fmt.Errorf("Palette %d: %v", i, err)
+		}
+		palettes = append(palettes, palette)
+	}
+
+	return palettes, nil
+}
+
+// load create the palette from its file or colours, dir is used for relative file paths
+func (pc *PaletteConfig) load(dir string) (ppu.Palette, error) {
+	if pc.File != "" {
+		path := pc.File
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+
+		palette, err := LoadPaletteFile(path)
+		if err != nil {
+			return palette, err
+		}
+		if pc.Name != "" {
+			palette.Name = pc.Name
+		}
+		return palette, nil
+	}
+
+	if pc.Name == "" {
+		return ppu.Palette{}, fmt.Errorf("Palette has no name")
+	}
+
+	bg, err := parseFourColors(pc.BG)
+	if err != nil {
+		return ppu.Palette{}, fmt.Errorf("%s bg: %v", pc.Name, err)
+	}
+	palette := ppu.NewPalette(pc.Name, bg)
+
+	if len(pc.OBP0) > 0 {
+		obp0, err := parseFourColors(pc.OBP0)
+		if err != nil {
+			return ppu.Palette{}, fmt.Errorf("%s obp0: %v", pc.Name, err)
+		}
+		palette.Colors[ppu.PaletteOBP0] = obp0
+		palette.Colors[ppu.PaletteOBP1] = obp0
+	}
+
+	if len(pc.OBP1) > 0 {
+		obp1, err := parseFourColors(pc.OBP1)
+		if err != nil {
+			return ppu.Palette{}, fmt.Errorf("%s obp1: %v", pc.Name, err)
+		}
+		palette.Colors[ppu.PaletteOBP1] = obp1
+	}
+
+	return palette, nil
+}
+
+// parseFourColors parse exactly 4 hex colours
+func parseFourColors(hexes []string) ([4]color.RGBA, error) {
+	if len(hexes) != 4 {
+		return [4]color.RGBA{}, fmt.Errorf("Need 4 colours, got %d", len(hexes))
+	}
+
+	colors, err := parseHexColors(hexes)
+	if err != nil {
+		return [4]color.RGBA{}, err
+	}
+
+	return [4]color.RGBA(colors), nil
+}
+
+// LoadPaletteFile load a palette from a JASC .pal file or a file with a hex
+// colour on each line. Files with 4 colours use them for everything, files with
+// 12 colours are split into the BG, OBP0 and OBP1 palettes
+func LoadPaletteFile(path string) (ppu.Palette, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return ppu.Palette{}, fmt.Errorf("Failed to open palette: %v", err)
+	}
+	defer file.Close()
+
+	colors, err := ParsePalette(file)
+	if err != nil {
+		return ppu.Palette{}, fmt.Errorf("Failed to read palette %s: %v", path, err)
+	}
+
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	palette := ppu.NewPalette(name, [4]color.RGBA(colors[:4]))
+	if len(colors) == 12 {
+		palette.Colors[ppu.PaletteOBP0] = [4]color.RGBA(colors[4:8])
+		palette.Colors[ppu.PaletteOBP1] = [4]color.RGBA(colors[8:12])
+	}
+
+	return palette, nil
+}
+
+// ParsePalette read the colours from a JASC .pal or hex palette, there has to
+// be either 4 or 12 colours
+func ParsePalette(r io.Reader) ([]color.RGBA, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	var colors []color.RGBA
+	var err error
+	if len(lines) > 0 && lines[0] == "JASC-PAL" {
+		colors, err = parseJASCColors(lines)
+	} else {
+		colors, err = parseHexColors(lines)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(colors) != 4 && len(colors) != 12 {
+		return nil, fmt.Errorf("Palette has %d colours, should have 4 or 12", len(colors))
+	}
+
+	return colors, nil
+}
+
+// parseJASCColors parse the lines of a JASC .pal file
+// The first 3 lines are the header, version and colour count
+func parseJASCColors(lines []string) ([]color.RGBA, error) {
+	if len(lines) < 3 {
+		return nil, fmt.Errorf("JASC palette is missing its header")
+	}
+
+	count, err := strconv.Atoi(lines[2])
+	if err != nil || count != len(lines)-3 {
+		return nil, fmt.Errorf("JASC palette colour count %q doesn't match its colours", lines[2])
+	}
+
+	colors := make([]color.RGBA, 0, count)
+	for _, line := range lines[3:] {
+		fields := strings.Fields(line)
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("Invalid JASC colour %q", line)
+		}
+
+		var rgb [3]uint8
+		for i, field := range fields {
+			val, err := strconv.ParseUint(field, 10, 8)
+			if err != nil {
+				return nil, fmt.Errorf("Invalid JASC colour %q", line)
+			}
+			rgb[i] = uint8(val)
+		}
+		colors = append(colors, color.RGBA{R: rgb[0], G: rgb[1], B: rgb[2], A: 0xFF})
+	}
+
+	return colors, nil
+}
+
+// parseHexColors parse colours given as RRGGBB with an optional leading #
+func parseHexColors(hexes []string) ([]color.RGBA, error) {
+	colors := make([]color.RGBA, 0, len(hexes))
+	for _, hex := range hexes {
+		val, err := strconv.ParseUint(strings.TrimPrefix(hex, "#"), 16, 32)
+		if err != nil || len(strings.TrimPrefix(hex, "#")) != 6 {
+			return nil, fmt.Errorf("Invalid hex colour %q", hex)
+		}
+		colors = append(colors, color.RGBA{R: uint8(val >> 16), G: uint8(val >> 8), B: uint8(val), A: 0xFF})
+	}
+
+	return colors, nil
+}
@@ -145,6 +145,43 @@ func (cpu *CPU) ResetDebug() {
 	cpu.PC = 0x0100
 }
 
+// ResetDebugCGB same as ResetDebug but with the registers the CGB boot rom
+// leaves, games check for A being 0x11 to know they're running on a CGB
+func (cpu *CPU) ResetDebugCGB() {
+	cpu.ResetDebug()
+	cpu.Reg.A = 0x11
+
+	cpu.SetFlag(C, false)
+	cpu.SetFlag(H, false)
+	cpu.SetFlag(N, false)
+	cpu.SetFlag(Z, true)
+
+	cpu.Reg.B = 0x00
+	cpu.Reg.C = 0x00
+	cpu.Reg.D = 0xFF
+	cpu.Reg.E = 0x56
+	cpu.Reg.H = 0x00
+	cpu.Reg.L = 0x0D
+}
+
+// ResetDebugSGB same as ResetDebug but with the registers the SGB boot rom leaves
+func (cpu *CPU) ResetDebugSGB() {
+	cpu.ResetDebug()
+	cpu.Reg.A = 0x01
+
+	cpu.SetFlag(C, false)
+	cpu.SetFlag(H, false)
+	cpu.SetFlag(N, false)
+	cpu.SetFlag(Z, false)
+
+	cpu.Reg.B = 0x00
+	cpu.Reg.C = 0x14
+	cpu.Reg.D = 0x00
+	cpu.Reg.E = 0x00
+	cpu.Reg.H = 0xC0
+	cpu.Reg.L = 0x60
+}
+
 // ResetFlag reset given flag to it's default
 func (cpu *CPU) ResetFlag(flag int) {
 	switch flag {
@@ -215,6 +252,11 @@ func (cpu *CPU) SetFlag(flag int, state bool) {
 // Step the step function for the cpu
 // Return cycle amount in M-cycles
 func (cpu *CPU) Step() int {
+	if stall := cpu.MMU.TakeDMAStall(); stall > 0 { // held up while the VRAM DMA copies
+		cpu.instrCycles = stall
+		return cpu.instrCycles
+	}
+
 	cpu.instrCycles = 1
 	if !cpu.Halted {
 		if cpu.checkInterrupts() {
@@ -958,7 +958,11 @@ func (cpu *CPU) Nop() {
 }
 
 // Stop switches the system to STOP mode
+// On CGB this is also how the game switches between normal and double speed
 func (cpu *CPU) Stop() {
+	if cpu.MMU.SwitchSpeed() {
+		return
+	}
 	// TODO - figure out how to like actually implement this
 }
 
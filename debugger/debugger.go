@@ -46,17 +46,12 @@ func DebugEmulatorDoctor(emu *emulator.Emulator) {
 	emu.CPU.ResetDebug()
 	count := 1
 	maxTests := 6500000 // set to 0 or below for infinite running >:3
-	debugger := Debugger{Emu: emu}
 	var serialOutput string
+	captureSerial(emu, &serialOutput)
 
 	for !strings.Contains(serialOutput, "Passed") && count != maxTests {
 		fmt.Printf("%v\n%v\n", emu.CPU.StringDoctor(), emu.Timer.String())
 		emu.CPU.Step()
-		serialWritten, data := debugger.checkSerialLink()
-		if serialWritten {
-			// fmt.Printf("%v", string(data))
-			serialOutput += string(data)
-		}
 
 		// time.Sleep(time.Nanosecond*2)
 
@@ -95,6 +90,7 @@ func DebugEmulator(emu *emulator.Emulator) {
 func DebugEmu(emu *emulator.Emulator) {
 	emu.CPU.ResetDebug()
 	d := Debugger{Emu: emu, ActivePanel: 0, fullSpeed: false}
+	captureSerial(emu, &d.serialOutput)
 	s, err := initTcell()
 	if err != nil {
 		log.Fatal(err)
@@ -125,10 +121,6 @@ func DebugEmu(emu *emulator.Emulator) {
 	}()
 
 	for d.running {
-		serialWritten, data := d.checkSerialLink()
-		if serialWritten {
-			d.serialOutput += string(data)
-		}
 		if !d.fullSpeed {
 			ev := d.Screen.PollEvent()
 			d.handleKeys(ev)
@@ -237,7 +229,7 @@ func (d *Debugger) drawCPUDataPanel(sepY int) {
 	firstLine := fmt.Sprintf("PC:0x%04X SP:0x%04X RegF:%v", cpu.PC, cpu.SP, cpu.Reg.F.String())
 	secondLine := fmt.Sprintf("Regs:%v", cpu.Reg.String())
 	thirdLine := fmt.Sprintf("PCMem: %v, %v, %v, %v", pc, pcOne, pcTwo, pcThree)
-	fourthLine := fmt.Sprintf("Timer: %v, FrameCycles: %v", d.Emu.Timer, d.Emu.CycleCount)
+	fourthLine := fmt.Sprintf("Timer: %v, Frame: %v", d.Emu.Timer, d.Emu.PPU.FrameCount)
 	fifthLine := fmt.Sprintf("SerialOutput: %s", d.serialOutput)	
 	drawText(d.Screen, startX, startY, maxX-1, endY, defStyle, firstLine)
 	drawText(d.Screen, startX, startY+1, maxX-1, endY, defStyle, secondLine)
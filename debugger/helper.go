@@ -2,7 +2,10 @@ package debugger
 
 import (
 	"fmt"
+	"io"
 
+	"github.com/TheOrnyx/dmg-go/emulator"
+	"github.com/TheOrnyx/dmg-go/serial"
 	"github.com/gdamore/tcell/v2"
 )
 
@@ -51,16 +54,42 @@ func updateDimensions(s tcell.Screen) {
 	maxY -= 1 // because the max is one off the screen
 }
 
-// checkSerialLink check whether data has been sent to the serial link and return it and the status
-func (d *Debugger) checkSerialLink() (bool, byte) {
-	if d.Emu.MMU.ReadByte(0xFF02) != 0 {
-		char := d.Emu.MMU.ReadByte(0xFF01)
-		d.Emu.MMU.WriteByte(0xFF02, 0)
-		return true, char
-		// fmt.Println("Found write")
-		// fmt.Printf("%v", char)
+// serialCapture a serial device that adds every byte the game sends to output,
+// passing them on to the device behind it (nil = nothing plugged in)
+type serialCapture struct {
+	output *string
+	device serial.Device
+}
+
+// captureSerial put a serialCapture in front of whatever is plugged into the emulators serial port
+func captureSerial(emu *emulator.Emulator, output *string) {
+	emu.Serial.Device = &serialCapture{output: output, device: emu.Serial.Device}
+}
+
+// Exchange add data to the output
+func (c *serialCapture) Exchange(data byte) byte {
+	*c.output += string(data)
+	if c.device != nil {
+		return c.device.Exchange(data)
+	}
+	return 0xFF
+}
+
+// Poll pass on transfers started by the device behind the capture
+func (c *serialCapture) Poll(listening bool, out byte) (byte, bool) {
+	if clock, ok := c.device.(serial.ExternalClock); ok {
+		return clock.Poll(listening, out)
+	}
+	return 0, false
+}
+
+// Close close the device behind the capture, so links are disconnected and the
+// printer saves its last page when the emulator unplugs it
+func (c *serialCapture) Close() error {
+	if closer, ok := c.device.(io.Closer); ok {
+		return closer.Close()
 	}
-	return false, 0
+	return nil
 }
 
 // initTcell initialize the tcell screen and return it
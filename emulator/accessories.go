@@ -0,0 +1,53 @@
+package emulator
+
+import (
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"log"
+	"os"
+
+	"github.com/TheOrnyx/dmg-go/cartridge"
+	"github.com/TheOrnyx/dmg-go/window"
+)
+
+var CameraImagePath string // the image the Pocket Camera takes photos of (empty = a test pattern)
+
+// connectAccessories hook the carts extra hardware (rumble, tilt, camera) up to
+// the renderer if they both support it
+func (e *Emulator) connectAccessories() {
+	switch mbc := e.MMU.Cart.MBC.(type) {
+	case *cartridge.MBC5:
+		if rumbler, ok := e.Renderer.(window.Rumbler); ok && mbc.HasRumble() {
+			mbc.OnRumble = rumbler.Rumble
+		}
+
+	case *cartridge.MBC7:
+		if tilter, ok := e.Renderer.(window.Tilter); ok {
+			mbc.Tilt = tilter.Tilt
+		}
+
+	case *cartridge.PocketCamera:
+		if CameraImagePath != "" {
+			mbc.Source = func() image.Image { return loadCameraImage(CameraImagePath) }
+		}
+	}
+}
+
+// loadCameraImage load the image at path for the camera, it's loaded for every
+// photo so it can be changed while running. nil is returned if it can't be loaded
+func loadCameraImage(path string) image.Image {
+	file, err := os.Open(path)
+	if err != nil {
+		log.Println("Failed to open camera image:", err)
+		return nil
+	}
+	defer file.Close()
+
+	img, _, err := image.Decode(file)
+	if err != nil {
+		log.Println("Failed to decode camera image:", err)
+		return nil
+	}
+	return img
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"path/filepath"
 	"runtime"
 	"time"
 
@@ -12,6 +13,8 @@ import (
 	"github.com/TheOrnyx/dmg-go/joypad"
 	"github.com/TheOrnyx/dmg-go/mmu"
 	"github.com/TheOrnyx/dmg-go/ppu"
+	"github.com/TheOrnyx/dmg-go/serial"
+	"github.com/TheOrnyx/dmg-go/sgb"
 	"github.com/TheOrnyx/dmg-go/timer"
 	"github.com/TheOrnyx/dmg-go/window"
 )
@@ -20,20 +23,38 @@ var FrameRate float64 = 59.7
 var frameDuration = time.Second / time.Duration(FrameRate)
 
 var SaveDirLoc string = "./Saves" // TODO - replace this with like a different directory
+var ROMOptions cartridge.LoadOptions // how roms are loaded
 
 // generateSaveDirLoc create the save directory location using
-// XDG_DATA_HOME or $HOME/.local/share if env doesn't exist
+// XDG_DATA_HOME or $HOME/.local/share if env doesn't exist, or the users
+// config directory (AppData on windows) on other systems. Screenshots go
+// in their own directory next to the save directory, prints go in the save directory
 func generateSaveDirLoc()  {
-	system := runtime.GOOS
-	if system == "linux" {
-		if loc, exists := os.LookupEnv("XDG_DATA_HOME"); exists {
-			SaveDirLoc = fmt.Sprintf("%v/dmg-go", loc)
-		} else {
-			SaveDirLoc = fmt.Sprintf("%v/.local/share/dmg-go", os.Getenv("HOME"))
-		}
-	} else {
-		// TODO - implement things for windows and stuff
+	dataDir, err := userDataDir()
+	if err != nil {
+		log.Println("Failed to find a directory for saves, using the current directory:", err)
+		return
+	}
+
+	SaveDirLoc = filepath.Join(dataDir, "dmg-go")
+	ScreenshotDirLoc = filepath.Join(dataDir, "dmg-go-screenshots")
+	PrintDirLoc = filepath.Join(SaveDirLoc, "prints")
+}
+
+// userDataDir get the directory the save directory goes in for this system
+func userDataDir() (string, error) {
+	if runtime.GOOS != "linux" {
+		return os.UserConfigDir()
 	}
+
+	if loc, exists := os.LookupEnv("XDG_DATA_HOME"); exists {
+		return loc, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".local", "share"), nil
 }
 
 type Emulator struct {
@@ -41,42 +62,77 @@ type Emulator struct {
 	MMU            *mmu.MMU
 	PPU            *ppu.PPU
 	Timer          *timer.Timer
+	Serial         *serial.Serial
 	Renderer       window.Screen
 	Joypad         *joypad.Joypad
-	CycleCount     int // the cycle count in T-Cycles!
+	SGB            *sgb.SGB      // the super game boy, nil unless emulating one
+	Recorder       VideoRecorder // records every rendered frame if set
 	frameStartTime time.Time
+	lastFrame      int // the ppu frame count at the end of the last frame
+	lastSave       time.Time // when the save file was last written, for autosaving
+	backedUp       bool      // whether the save from before this session has been backed up yet
+	saveBroken     bool      // whether the save failed to load, it's never written over if so
 }
 
 // NewEmulator Start a new emulator, load the rom in the given path and return the emulator instance
 func NewEmulator(romPath string, renderer window.Screen) (*Emulator, error) {
-	emu := new(Emulator)
 	generateSaveDirLoc()
-	rom, err := os.ReadFile(romPath)
+	rom, err := cartridge.ReadROMFile(romPath, ROMOptions)
+	if err != nil {
+		return nil, err
+	}
+	rom, err = patchROM(romPath, rom)
 	if err != nil {
 		return nil, err
 	}
 
-	cart, err := cartridge.LoadROM(rom)
+	emu, err := newEmulator(rom, renderer)
 	if err != nil {
 		return nil, err
 	}
+	fmt.Println(emu.DebugInfo())
+	if err := emu.restorePalette(); err != nil {
+		log.Println("Failed to load the palette for this rom:", err)
+	}
+	if err := emu.LoadSaveFile(); err != nil {
+		log.Printf("ERROR: %v - the game is running without its save and won't save, so the save file isn't overwritten", err)
+		emu.saveBroken = true
+	}
+	return emu, nil
+}
+
+// NewHeadlessEmulator start a new emulator for rom without a window. Nothing is
+// read from or written to the save directory and no palette is restored, so
+// it's safe for tests and tooling
+func NewHeadlessEmulator(rom []byte) (*Emulator, error) {
+	return newEmulator(rom, &HeadlessScreen{})
+}
+
+// newEmulator load rom and connect up the hardware
+func newEmulator(rom []byte, renderer window.Screen) (*Emulator, error) {
+	emu := new(Emulator)
+	cart, err := cartridge.LoadROM(rom, ROMOptions)
+	if err != nil {
+		return nil, err
+	}
+	for _, warning := range cart.Warnings {
+		log.Println("Warning:", warning)
+	}
 
 	emu.Timer = timer.NewTimer(emu.RequestInterrupt)
+	emu.Serial = serial.NewSerial(emu.RequestInterrupt)
 	emu.Renderer = renderer
 	emu.Joypad = joypad.NewJoypad(emu.RequestInterrupt)
 	emu.Joypad.ResetInput()
 	emu.PPU = ppu.NewPPU(emu.Timer, emu.RequestInterrupt)
-	emu.MMU = mmu.NewMMU(cart, emu.Timer, emu.PPU, emu.Joypad)
+	emu.MMU = mmu.NewMMU(cart, emu.Timer, emu.PPU, emu.Joypad, emu.Serial)
 	emu.CPU, _ = cpu.NewCPU(emu.MMU, emu.Timer)
-	emu.CPU.ResetDebug()
-	emu.frameStartTime = time.Now()
-	fmt.Println(emu.DebugInfo())
-	if true {
-		err := emu.LoadSaveFile()
-		if err != nil {
-			// return nil, err
-		}
+	if err := emu.setupModel(cart); err != nil {
+		return nil, err
 	}
+	emu.connectAccessories()
+	emu.frameStartTime = time.Now()
+	emu.lastSave = time.Now()
 	return emu, nil
 }
 
@@ -99,19 +155,17 @@ func (e *Emulator) RunEmulator() {
 // Returns whether or not emu should close
 func (e *Emulator) Step() bool {
 	closeEmu := false
-	mCycles := e.CPU.Step()
-	tCycles := mCycles * 4
-	e.Timer.TickT(tCycles)
-	e.PPU.Step(uint16(tCycles))
-	e.CycleCount += tCycles
+	e.stepHardware()
 
-	if float64(e.CycleCount) >= (cpu.ClockSpeed / FrameRate) { // finish frame
-		e.CycleCount = 0
+	if e.PPU.FrameCount != e.lastFrame { // finish frame once the ppu hits vblank
+		e.lastFrame = e.PPU.FrameCount
 		inputs, close := e.Renderer.GetInput()
 		closeEmu = close
 		e.Joypad.HandleInput(inputs)
+		e.handleHotkeys(e.Renderer.Hotkeys())
 		e.RenderScreen()
 		e.PPU.Screen.Reset()
+		e.autosave()
 
 		elapsedTime := time.Since(e.frameStartTime)
 		sleepTime := frameDuration - elapsedTime
@@ -124,44 +178,65 @@ func (e *Emulator) Step() bool {
 	return closeEmu
 }
 
-// LoadSaveFile load a save file for a game if exists
-func (e *Emulator) LoadSaveFile() error {
-	saveLoc := fmt.Sprintf("%s/%s", SaveDirLoc, e.MMU.Cart.SaveTitle())
-	if _, err := os.Stat(saveLoc); err != nil {
-		return nil
+// handleHotkeys perform the actions for the hotkeys pressed this frame
+func (e *Emulator) handleHotkeys(hotkeys []window.Hotkey) {
+	for _, hotkey := range hotkeys {
+		switch hotkey {
+		case window.HotkeyScreenshot:
+			path, err := e.TakeScreenshot()
+			if err != nil {
+				log.Println("Failed to take screenshot:", err)
+				continue
+			}
+			log.Println("Saved screenshot to", path)
+		}
 	}
+}
 
-	save, err := os.Open(saveLoc)
-	if err != nil {
-		return fmt.Errorf("Failed to open save file: %v", err)
+// stepHardware step the cpu once and tick the timer and ppu to match
+// In CGB double speed the ppu runs at half the rate of the cpu and timer
+// Returns the amount of T-cycles taken
+func (e *Emulator) stepHardware() int {
+	mCycles := e.CPU.Step()
+	tCycles := mCycles * 4
+	e.Timer.TickT(tCycles)
+	e.Serial.Tick(tCycles)
+	if e.Timer.DoubleSpeed() {
+		e.PPU.Step(uint16(tCycles / 2))
+	} else {
+		e.PPU.Step(uint16(tCycles))
 	}
-	defer save.Close()
+	return tCycles
+}
 
-	err = e.MMU.Cart.MBC.LoadFile(save)
-	if err != nil {
-		return fmt.Errorf("Failed to load Save file to RAM: %v", err)
+// RunFrames run the emulator as fast as possible until the ppu has
+// finished the given amount of frames. Input, rendering and frame
+// limiting are skipped so the last finished frame is left in PPU.Screen
+func (e *Emulator) RunFrames(frames int) {
+	target := e.PPU.FrameCount + frames
+	for e.PPU.FrameCount < target {
+		e.stepHardware()
+		if e.PPU.FrameCount != e.lastFrame {
+			e.lastFrame = e.PPU.FrameCount
+			if e.PPU.FrameCount < target {
+				e.PPU.Screen.Reset()
+			}
+		}
 	}
-
-	return nil
 }
 
 // CloseEmulator close the emulator and write saves if needed
 func (e *Emulator) CloseEmulator() {
 	e.Renderer.CloseScreen()
-	if e.MMU.Cart.RAMSize == 0 {
-		return
-	}
-	
-	os.Mkdir(SaveDirLoc, 0750)
-	file, err := os.Create(fmt.Sprintf("%s/%s", SaveDirLoc, e.CPU.MMU.Cart.SaveTitle()))
-	if err != nil {
-		log.Fatalf("Failed to create file: %v", err)
+	e.stopRecording()
+	e.UnplugSerial()
+	if err := e.savePalette(); err != nil {
+		log.Println("Failed to save the palette for this rom:", err)
 	}
-	defer file.Close()
-
-	err = e.MMU.Cart.MBC.SaveFile(file)
-	if err != nil {
-		log.Fatalf("Failed to Save file: %v", err)
+	if e.MMU.Cart.RAMDirty { // nothing to write or back up if the save didn't change
+		if err := e.WriteSaveFile(); err != nil {
+			log.Println("Failed to write save:", err)
+		}
 	}
 }
 
@@ -169,6 +244,25 @@ func (e *Emulator) CloseEmulator() {
 func (e *Emulator) RenderScreen() {
 	e.Renderer.ClearScreen()
 	e.Renderer.RenderScreen(&e.PPU.Screen)
+
+	if e.Recorder != nil {
+		if err := e.Recorder.WriteFrame(e.PPU.Screen.Image(e.Renderer.Palette())); err != nil {
+			log.Println("Failed to record frame, stopping recording:", err)
+			e.stopRecording()
+		}
+	}
+}
+
+// stopRecording close the recorder if recording
+func (e *Emulator) stopRecording() {
+	if e.Recorder == nil {
+		return
+	}
+
+	if err := e.Recorder.Close(); err != nil {
+		log.Println("Failed to close recording:", err)
+	}
+	e.Recorder = nil
 }
 
 // DebugInfo print debug info about the emulator
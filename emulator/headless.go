@@ -0,0 +1,37 @@
+package emulator
+
+import (
+	"github.com/TheOrnyx/dmg-go/ppu"
+	"github.com/TheOrnyx/dmg-go/window"
+)
+
+// HeadlessScreen a screen that doesn't draw anything, used to run the
+// emulator without a window (tests, tooling etc)
+type HeadlessScreen struct{}
+
+// ClearScreen does nothing
+func (h *HeadlessScreen) ClearScreen() {}
+
+// RenderScreen does nothing
+func (h *HeadlessScreen) RenderScreen(screen *ppu.Screen) {}
+
+// CloseScreen does nothing
+func (h *HeadlessScreen) CloseScreen() {}
+
+// GetInput always returns no buttons pressed
+func (h *HeadlessScreen) GetInput() (inputs [8]bool, closeEmu bool) {
+	return inputs, false
+}
+
+// Hotkeys always returns no hotkeys
+func (h *HeadlessScreen) Hotkeys() []window.Hotkey {
+	return nil
+}
+
+// Palette return the grey palette
+func (h *HeadlessScreen) Palette() ppu.Palette {
+	return ppu.GreyPalette
+}
+
+// SetPalette does nothing, the grey palette is always used
+func (h *HeadlessScreen) SetPalette(name string) {}
@@ -0,0 +1,34 @@
+package emulator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/TheOrnyx/dmg-go/serial"
+)
+
+// StartLink plug a link cable into the serial port, spec is either
+// "listen :port" to wait for another emulator or "connect host:port" to connect to one
+func (e *Emulator) StartLink(spec string) error {
+	fields := strings.Fields(spec)
+	if len(fields) != 2 {
+		return fmt.Errorf("Invalid link %q, should be \"listen :port\" or \"connect host:port\"", spec)
+	}
+
+	var link *serial.Link
+	var err error
+	switch fields[0] {
+	case "listen":
+		link, err = serial.Listen(fields[1])
+	case "connect":
+		link, err = serial.Dial(fields[1])
+	default:
+		return fmt.Errorf("Unknown link mode %q, should be listen or connect", fields[0])
+	}
+	if err != nil {
+		return err
+	}
+
+	e.Serial.Device = link
+	return nil
+}
@@ -0,0 +1,65 @@
+package emulator
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/TheOrnyx/dmg-go/cartridge"
+	"github.com/TheOrnyx/dmg-go/ppu"
+	"github.com/TheOrnyx/dmg-go/sgb"
+)
+
+// The models that can be emulated
+const (
+	ModelAuto = "auto" // CGB for carts with CGB support, DMG for everything else
+	ModelDMG  = "dmg"  // always a DMG, even for carts with CGB support
+	ModelCGB  = "cgb"  // always a CGB, DMG only carts are colourised like on real hardware
+	ModelSGB  = "sgb"  // a DMG in a Super Game Boy, carts with SGB support can colour the screen and draw a border
+)
+
+var Model string = ModelAuto // the model to emulate
+var CompatCombo string       // the button combo to pick the colours for DMG carts on a CGB (empty = use the title)
+
+// setupModel set up the hardware for the model being emulated with cart
+func (e *Emulator) setupModel(cart *cartridge.Cartridge) error {
+	switch Model {
+	case ModelAuto, ModelDMG, ModelCGB, ModelSGB:
+	default:
+		return fmt.Errorf("Unknown model %q, should be auto, dmg, cgb or sgb", Model)
+	}
+
+	switch {
+	case Model == ModelDMG:
+		if cart.HasCGBSupport && cart.ROM[0x0143] == 0xC0 {
+			log.Println("Warning: this cart only supports the CGB and will probably not work on a DMG")
+		}
+		e.CPU.ResetDebug()
+
+	case Model == ModelSGB:
+		if !cart.HasSGBSupport {
+			log.Println("Warning: this cart doesn't support the SGB, it will only get the default colours")
+		}
+		e.SGB = sgb.NewSGB(e.PPU, e.Joypad, cart.HasSGBSupport)
+		e.CPU.ResetDebugSGB()
+
+	case cart.HasCGBSupport:
+		e.MMU.EnableCGB()
+		e.CPU.ResetDebugCGB()
+
+	case Model == ModelCGB:
+		palette := ppu.FindCompatPalette(cart.IsNintendo(), cart.TitleChecksum(), cart.ROM[0x0137])
+		if CompatCombo != "" {
+			var err error
+			if palette, err = ppu.CompatComboPalette(CompatCombo); err != nil {
+				return err
+			}
+		}
+		e.PPU.EnableCompatMode(palette)
+		e.CPU.ResetDebugCGB()
+
+	default:
+		e.CPU.ResetDebug()
+	}
+
+	return nil
+}
@@ -0,0 +1,69 @@
+package emulator
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+const (
+	mooneyeDir       = "../Data/Roms/mooneye"
+	mooneyeMaxFrames = 600  // frames to wait for a rom to finish before failing
+	mooneyeBreakOp   = 0x40 // LD B, B, the mooneye roms run it once they're done
+)
+
+// mooneyeROMs the emulator-only mooneye roms that have to pass
+var mooneyeROMs = []string{
+	"mbc1/bits_bank1.gb",
+	"mbc1/bits_bank2.gb",
+	"mbc1/bits_mode.gb",
+	"mbc1/bits_ramg.gb",
+	"mbc1/multicart_rom_8Mb.gb",
+	"mbc1/ram_256kb.gb",
+	"mbc1/ram_64kb.gb",
+	"mbc1/rom_16Mb.gb",
+	"mbc1/rom_1Mb.gb",
+	"mbc1/rom_2Mb.gb",
+	"mbc1/rom_4Mb.gb",
+	"mbc1/rom_512kb.gb",
+	"mbc1/rom_8Mb.gb",
+	"mbc2/bits_ramg.gb",
+	"mbc2/bits_romb.gb",
+	"mbc2/bits_unused.gb",
+	"mbc2/ram.gb",
+	"mbc2/rom_1Mb.gb",
+	"mbc2/rom_2Mb.gb",
+	"mbc2/rom_512kb.gb",
+}
+
+// TestMooneye run each of the mooneye roms until they hit LD B, B and check
+// the registers hold the fibonacci numbers they leave on a pass
+func TestMooneye(t *testing.T) {
+	for _, name := range mooneyeROMs {
+		t.Run(name, func(t *testing.T) {
+			emu := newTestEmulator(t, filepath.Join(mooneyeDir, name))
+
+			if !runUntilBreakpoint(emu, mooneyeMaxFrames) {
+				t.Fatalf("rom didn't finish within %d frames", mooneyeMaxFrames)
+			}
+
+			reg := emu.CPU.Reg
+			got := [6]byte{reg.B, reg.C, reg.D, reg.E, reg.H, reg.L}
+			if got != [6]byte{3, 5, 8, 13, 21, 34} {
+				t.Errorf("rom failed, registers %s", reg.String())
+			}
+		})
+	}
+}
+
+// runUntilBreakpoint step the emulator until the next instruction is LD B, B
+// Returns false if it wasn't reached within maxFrames frames
+func runUntilBreakpoint(emu *Emulator, maxFrames int) bool {
+	target := emu.PPU.FrameCount + maxFrames
+	for emu.PPU.FrameCount < target {
+		if emu.MMU.ReadByte(emu.CPU.PC) == mooneyeBreakOp {
+			return true
+		}
+		emu.stepHardware()
+	}
+	return false
+}
@@ -0,0 +1,75 @@
+package emulator
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const paletteFileName = "palettes.json" // the file in SaveDirLoc holding the palette used for each rom
+
+// romTitle the title of the loaded rom as used in file names
+func (e *Emulator) romTitle() string {
+	return strings.TrimSuffix(e.MMU.Cart.SaveTitle(), ".save")
+}
+
+// loadROMPalettes read the palette names saved for each rom title
+func loadROMPalettes() (map[string]string, error) {
+	romPalettes := make(map[string]string)
+
+	data, err := os.ReadFile(filepath.Join(SaveDirLoc, paletteFileName))
+	if errors.Is(err, fs.ErrNotExist) {
+		return romPalettes, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, &romPalettes); err != nil {
+		return nil, err
+	}
+
+	return romPalettes, nil
+}
+
+// restorePalette switch the renderer to the palette last used with this rom
+func (e *Emulator) restorePalette() error {
+	romPalettes, err := loadROMPalettes()
+	if err != nil {
+		return err
+	}
+
+	if name, found := romPalettes[e.romTitle()]; found {
+		e.Renderer.SetPalette(name)
+	}
+
+	return nil
+}
+
+// savePalette remember the palette the renderer is using for this rom
+func (e *Emulator) savePalette() error {
+	romPalettes, err := loadROMPalettes()
+	if err != nil {
+		return err
+	}
+
+	name := e.Renderer.Palette().Name
+	if romPalettes[e.romTitle()] == name {
+		return nil
+	}
+	romPalettes[e.romTitle()] = name
+
+	data, err := json.MarshalIndent(romPalettes, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(SaveDirLoc, 0750); err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(SaveDirLoc, paletteFileName), data, 0640)
+}
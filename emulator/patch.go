@@ -0,0 +1,61 @@
+package emulator
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/TheOrnyx/dmg-go/patch"
+)
+
+var PatchPaths []string // patches to apply to the rom in order, if empty a patch next to the rom is used
+
+// patchROM apply PatchPaths to rom, or the patch with the same name as the rom
+// next to it if there aren't any
+func patchROM(romPath string, rom []byte) ([]byte, error) {
+	paths := PatchPaths
+	if len(paths) == 0 {
+		if found := findPatch(romPath); found != "" {
+			paths = []string{found}
+		}
+	}
+
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to read patch: %v", err)
+		}
+
+		rom, err = patch.Apply(rom, data)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to apply patch %s: %v", path, err)
+		}
+		log.Printf("Applied %s patch %s", patch.Format(data), path)
+	}
+
+	return rom, nil
+}
+
+// findPatch find a patch next to the rom with the same name (game.gb or game.gb.gz
+// look for game.ips, game.bps then game.ups), "" if there isn't one
+func findPatch(romPath string) string {
+	if romPath == "-" {
+		return ""
+	}
+
+	base := romPath
+	for _, ext := range []string{".gz", ".zip", ".gb", ".gbc"} {
+		if strings.EqualFold(filepath.Ext(base), ext) {
+			base = base[:len(base)-len(ext)]
+		}
+	}
+
+	for _, ext := range patch.Extensions {
+		if _, err := os.Stat(base + ext); err == nil {
+			return base + ext
+		}
+	}
+	return ""
+}
@@ -0,0 +1,50 @@
+package emulator
+
+import (
+	"fmt"
+	"image"
+	"image/png"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/TheOrnyx/dmg-go/serial"
+)
+
+var PrintDirLoc string = "./Prints" // where the printers output is saved
+
+// ConnectPrinter plug a Game Boy Printer into the serial port, each print is
+// saved as a png in PrintDirLoc
+func (e *Emulator) ConnectPrinter() {
+	e.Serial.Device = serial.NewPrinter(func(img *image.Gray) {
+		path, err := e.savePrint(img)
+		if err != nil {
+			log.Println("Failed to save print:", err)
+			return
+		}
+		log.Println("Saved print to", path)
+	})
+}
+
+// savePrint save img to a timestamped png in PrintDirLoc and return the path it was saved to
+func (e *Emulator) savePrint(img *image.Gray) (string, error) {
+	name := fmt.Sprintf("%s_%s.png", e.romTitle(), time.Now().Format("2006-01-02_15-04-05.000"))
+	path := filepath.Join(PrintDirLoc, name)
+
+	if err := os.MkdirAll(PrintDirLoc, 0750); err != nil {
+		return "", fmt.Errorf("Failed to create print directory: %v", err)
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return "", fmt.Errorf("Failed to create print file: %v", err)
+	}
+	defer file.Close()
+
+	if err := png.Encode(file, img); err != nil {
+		return "", fmt.Errorf("Failed to encode print: %v", err)
+	}
+
+	return path, nil
+}
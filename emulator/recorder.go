@@ -0,0 +1,131 @@
+package emulator
+
+import (
+	"bufio"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// NOTE - only video is recorded as there's no audio emulation yet
+
+// VideoRecorder writes every completed frame to a video output
+type VideoRecorder interface {
+	WriteFrame(img *image.RGBA) error
+	Close() error
+}
+
+// NewVideoRecorder create a recorder for path, paths ending in .y4m are written
+// as a raw Y4M stream and anything else is used as a directory for a PNG sequence
+func NewVideoRecorder(path string) (VideoRecorder, error) {
+	if strings.EqualFold(filepath.Ext(path), ".y4m") {
+		return newY4MRecorder(path)
+	}
+
+	return newPNGSequenceRecorder(path)
+}
+
+////////////////
+// Y4M Stream //
+////////////////
+
+// y4mRecorder writes frames as an uncompressed 4:4:4 YUV4MPEG2 stream
+// that can be muxed or encoded later with something like ffmpeg
+type y4mRecorder struct {
+	file   *os.File
+	writer *bufio.Writer
+	planes [3][]byte // the Y, Cb and Cr planes for the current frame
+}
+
+// newY4MRecorder create the file at path and write the stream header
+func newY4MRecorder(path string) (*y4mRecorder, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to create video file: %v", err)
+	}
+
+	r := &y4mRecorder{file: file, writer: bufio.NewWriter(file)}
+	for i := range r.planes {
+		r.planes[i] = make([]byte, 160*144)
+	}
+
+	// the DMG refreshes at 4194304/70224 Hz (~59.73)
+	_, err = fmt.Fprintf(r.writer, "YUV4MPEG2 W160 H144 F4194304:70224 Ip A1:1 C444 XCOLORRANGE=FULL\n")
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("Failed to write video header: %v", err)
+	}
+
+	return r, nil
+}
+
+// WriteFrame convert img to YCbCr and append it to the stream
+func (r *y4mRecorder) WriteFrame(img *image.RGBA) error {
+	for y := range 144 {
+		for x := range 160 {
+			col := img.RGBAAt(x, y)
+			i := y*160 + x
+			r.planes[0][i], r.planes[1][i], r.planes[2][i] = color.RGBToYCbCr(col.R, col.G, col.B)
+		}
+	}
+
+	if _, err := r.writer.WriteString("FRAME\n"); err != nil {
+		return err
+	}
+	for _, plane := range r.planes {
+		if _, err := r.writer.Write(plane); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Close flush the stream and close the file
+func (r *y4mRecorder) Close() error {
+	if err := r.writer.Flush(); err != nil {
+		r.file.Close()
+		return err
+	}
+	return r.file.Close()
+}
+
+//////////////////
+// PNG Sequence //
+//////////////////
+
+// pngSequenceRecorder writes each frame as a numbered png in a directory
+type pngSequenceRecorder struct {
+	dir   string
+	frame int // the number of the next frame
+}
+
+// newPNGSequenceRecorder create the directory the frames are written to
+func newPNGSequenceRecorder(dir string) (*pngSequenceRecorder, error) {
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return nil, fmt.Errorf("Failed to create video directory: %v", err)
+	}
+
+	return &pngSequenceRecorder{dir: dir}, nil
+}
+
+// WriteFrame write img to the next numbered png
+func (r *pngSequenceRecorder) WriteFrame(img *image.RGBA) error {
+	file, err := os.Create(filepath.Join(r.dir, fmt.Sprintf("frame_%06d.png", r.frame)))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	r.frame++
+	return png.Encode(file, img)
+}
+
+// Close nothing to close as each frame is its own file
+func (r *pngSequenceRecorder) Close() error {
+	return nil
+}
@@ -0,0 +1,150 @@
+package emulator
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+var SaveBackups = 3                     // the number of older saves kept as name.save.1 (newest) to name.save.N
+var AutosaveInterval = 10 * time.Second // how often the cart ram is written if the game changed it (0 = only on exit)
+
+// savePath the path of the save file for the cart
+func (e *Emulator) savePath() string {
+	return filepath.Join(SaveDirLoc, e.MMU.Cart.SaveName())
+}
+
+// LoadSaveFile load a save file for a game if exists
+// Saves from before they were named with the rom checksum are loaded if there's no newer one
+func (e *Emulator) LoadSaveFile() error {
+	saveLoc := e.savePath()
+	if _, err := os.Stat(saveLoc); err != nil {
+		saveLoc = filepath.Join(SaveDirLoc, e.MMU.Cart.SaveTitle())
+		if _, err := os.Stat(saveLoc); err != nil {
+			return nil
+		}
+		log.Printf("Loading old save %s, it'll be saved as %s", saveLoc, e.savePath())
+	}
+
+	save, err := os.Open(saveLoc)
+	if err != nil {
+		return fmt.Errorf("Failed to open save file: %v", err)
+	}
+	defer save.Close()
+
+	err = e.MMU.Cart.MBC.LoadFile(save)
+	if err != nil {
+		return fmt.Errorf("Failed to load Save file to RAM: %v", err)
+	}
+
+	return nil
+}
+
+// WriteSaveFile write the cart ram to the save file if the cart has a battery. The
+// first write each session moves the old save into the backups. Nothing is written
+// if the save failed to load so it isn't replaced by a blank one
+func (e *Emulator) WriteSaveFile() error {
+	if !e.MMU.Cart.MBC.HasBattery() {
+		return nil
+	}
+	if e.saveBroken {
+		return fmt.Errorf("Not writing the save, %s failed to load", e.savePath())
+	}
+
+	if err := os.MkdirAll(SaveDirLoc, 0750); err != nil {
+		return fmt.Errorf("Failed to create save directory: %v", err)
+	}
+
+	path := e.savePath()
+	err := writeFileAtomic(path, e.MMU.Cart.MBC.SaveFile, func() error {
+		if e.backedUp {
+			return nil
+		}
+		e.backedUp = true
+		return rotateBackups(path, SaveBackups)
+	})
+	if err != nil {
+		return err
+	}
+
+	e.MMU.Cart.RAMDirty = false
+	e.lastSave = time.Now()
+	return nil
+}
+
+// autosave write the save file if the game has written to the cart ram and it's
+// been AutosaveInterval since the last write
+func (e *Emulator) autosave() {
+	if AutosaveInterval <= 0 || e.saveBroken || !e.MMU.Cart.RAMDirty || time.Since(e.lastSave) < AutosaveInterval {
+		return
+	}
+
+	if err := e.WriteSaveFile(); err != nil {
+		log.Println("Failed to autosave:", err)
+		e.lastSave = time.Now() // don't retry every frame
+	}
+}
+
+// writeFileAtomic write a file with write through a temporary file that's renamed
+// over path once it's complete, so a crash never leaves a half written file.
+// beforeRename is run just before the rename
+func writeFileAtomic(path string, write func(io.Writer) error, beforeRename func() error) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("Failed to create save file: %v", err)
+	}
+	defer os.Remove(tmp.Name()) // does nothing once it's renamed
+
+	if err := write(tmp); err != nil {
+		tmp.Close()
+		return fmt.Errorf("Failed to write save file: %v", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("Failed to write save file: %v", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("Failed to write save file: %v", err)
+	}
+
+	if err := beforeRename(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("Failed to replace save file: %v", err)
+	}
+	return nil
+}
+
+// rotateBackups copy path to path.1 after moving path.1 to path.2 and so on,
+// dropping the oldest past count. path is copied so there's always a save there
+func rotateBackups(path string, count int) error {
+	if count <= 0 {
+		return nil
+	}
+	if _, err := os.Stat(path); err != nil {
+		return nil // nothing to back up
+	}
+
+	for i := count - 1; i >= 1; i-- {
+		from := fmt.Sprintf("%s.%d", path, i)
+		if _, err := os.Stat(from); err != nil {
+			continue
+		}
+		if err := os.Rename(from, fmt.Sprintf("%s.%d", path, i+1)); err != nil {
+			return fmt.Errorf("Failed to rotate save backups: %v", err)
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("Failed to back up save: %v", err)
+	}
+	if err := os.WriteFile(path+".1", data, 0640); err != nil {
+		return fmt.Errorf("Failed to back up save: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,173 @@
+package emulator
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/TheOrnyx/dmg-go/cartridge"
+)
+
+func TestWriteSaveRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "GAME-12345678.save")
+	write := func(session int) {
+		data := fmt.Sprintf("session %d", session)
+		err := writeFileAtomic(path, func(w io.Writer) error {
+			_, err := io.WriteString(w, data)
+			return err
+		}, func() error { return rotateBackups(path, 2) })
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for session := 1; session <= 4; session++ {
+		write(session)
+	}
+
+	for name, want := range map[string]string{"": "session 4", ".1": "session 3", ".2": "session 2"} {
+		data, err := os.ReadFile(path + name)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+		} else if string(data) != want {
+			t.Errorf("save%s has %q, want %q", name, data, want)
+		}
+	}
+
+	if _, err := os.Stat(path + ".3"); err == nil {
+		t.Error("more backups than asked for were kept")
+	}
+	if matches, _ := filepath.Glob(path + ".tmp*"); len(matches) != 0 {
+		t.Errorf("temporary files were left behind: %v", matches)
+	}
+}
+
+// setupSaveTest write a MBC1+RAM+BATTERY rom with 8KiB of ram to a temporary
+// directory and point the save directory there too. Returns the rom and save paths
+func setupSaveTest(t *testing.T) (romPath, savePath string) {
+	dir := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", dir)
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("HOME", dir)
+
+	rom := make([]byte, 0x8000)
+	copy(rom[0x0134:], "SAVETEST")
+	rom[0x0147] = 0x03 // MBC1+RAM+BATTERY
+	rom[0x0149] = 0x02 // 8KiB of ram
+	romPath = filepath.Join(dir, "savetest.gb")
+	if err := os.WriteFile(romPath, rom, 0640); err != nil {
+		t.Fatal(err)
+	}
+
+	cart, err := cartridge.LoadROM(rom, cartridge.LoadOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	generateSaveDirLoc()
+	if err := os.MkdirAll(SaveDirLoc, 0750); err != nil {
+		t.Fatal(err)
+	}
+	return romPath, filepath.Join(SaveDirLoc, cart.SaveName())
+}
+
+// newSaveTestEmulator start an emulator for romPath, loading its save
+func newSaveTestEmulator(t *testing.T, romPath string) *Emulator {
+	emu, err := NewEmulator(romPath, &HeadlessScreen{})
+	if err != nil {
+		t.Fatalf("Failed to create emulator: %v", err)
+	}
+	return emu
+}
+
+// writeCartRAM enable the cart ram and write data to the start of it
+func writeCartRAM(emu *Emulator, data byte) {
+	emu.MMU.WriteByte(0x0000, 0x0A)
+	emu.MMU.WriteByte(0xA000, data)
+}
+
+// forceAutosave autosave as if AutosaveInterval had passed since the last save
+func forceAutosave(emu *Emulator) {
+	emu.lastSave = time.Now().Add(-AutosaveInterval)
+	emu.autosave()
+}
+
+func TestAutosave(t *testing.T) {
+	romPath, savePath := setupSaveTest(t)
+	emu := newSaveTestEmulator(t, romPath)
+
+	writeCartRAM(emu, 0x00) // the ram is already 0 so nothing changed
+	forceAutosave(emu)
+	if _, err := os.Stat(savePath); err == nil {
+		t.Fatal("Autosaved without the ram changing")
+	}
+
+	writeCartRAM(emu, 0x42)
+	if !emu.MMU.Cart.RAMDirty {
+		t.Fatal("Changing the ram didn't mark it dirty")
+	}
+	forceAutosave(emu)
+	save, err := os.ReadFile(savePath)
+	if err != nil {
+		t.Fatalf("Didn't autosave: %v", err)
+	}
+	if len(save) != 0x2000 || save[0] != 0x42 {
+		t.Errorf("Autosaved %d bytes starting with 0x%02X, want 0x2000 starting with 0x42", len(save), save[0])
+	}
+	if emu.MMU.Cart.RAMDirty {
+		t.Error("Ram still dirty after autosaving")
+	}
+}
+
+func TestCloseKeepsBackupsWithoutChanges(t *testing.T) {
+	romPath, savePath := setupSaveTest(t)
+	save := bytes.Repeat([]byte{0x11}, 0x2000)
+	if err := os.WriteFile(savePath, save, 0640); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(savePath+".1", []byte("older save"), 0640); err != nil {
+		t.Fatal(err)
+	}
+
+	for session := 0; session < SaveBackups+1; session++ {
+		emu := newSaveTestEmulator(t, romPath)
+		writeCartRAM(emu, 0x11) // same as what was loaded
+		emu.CloseEmulator()
+	}
+
+	if backup, _ := os.ReadFile(savePath + ".1"); string(backup) != "older save" {
+		t.Errorf("Backup replaced with %q by sessions that didn't change the save", backup)
+	}
+	if _, err := os.Stat(savePath + ".2"); err == nil {
+		t.Error("Backups rotated by sessions that didn't change the save")
+	}
+
+	emu := newSaveTestEmulator(t, romPath)
+	writeCartRAM(emu, 0x22)
+	emu.CloseEmulator()
+	if backup, _ := os.ReadFile(savePath + ".1"); !bytes.Equal(backup, save) {
+		t.Error("Closing after changing the save didn't back up the old one")
+	}
+}
+
+func TestBrokenSaveNotOverwritten(t *testing.T) {
+	romPath, savePath := setupSaveTest(t)
+	if err := os.WriteFile(savePath, []byte("cut short"), 0640); err != nil {
+		t.Fatal(err)
+	}
+
+	emu := newSaveTestEmulator(t, romPath)
+	writeCartRAM(emu, 0x42)
+	forceAutosave(emu)
+	emu.CloseEmulator()
+
+	if save, _ := os.ReadFile(savePath); string(save) != "cut short" {
+		t.Errorf("Save that failed to load was replaced with %d bytes", len(save))
+	}
+	if _, err := os.Stat(savePath + ".1"); err == nil {
+		t.Error("Save that failed to load was rotated into the backups")
+	}
+}
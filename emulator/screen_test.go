@@ -0,0 +1,196 @@
+package emulator
+
+import (
+	"flag"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/TheOrnyx/dmg-go/cartridge"
+	"github.com/TheOrnyx/dmg-go/ppu"
+)
+
+var updateRefs = flag.Bool("update", false, "overwrite the reference images with the current output")
+
+const (
+	failureDir     = "testdata/failures" // where the actual and diff images are written on a mismatch
+	homebrewDir    = "../Data/Roms/homebrew"
+	homebrewFrames = 120 // frames to run homebrew roms for before comparing
+)
+
+// screenTest a rom to run for frames amount of frames and compare with the reference image
+type screenTest struct {
+	name      string
+	rom       string
+	reference string
+	frames    int
+}
+
+var screenTests = []screenTest{
+	{"dmg-acid2", "../Data/Roms/dmg-acid2/dmg-acid2.gb", "../Data/Roms/dmg-acid2/reference-dmg.png", 30},
+}
+
+// TestScreenRegression run each of the screen tests and compare against their
+// reference image. Homebrew roms in homebrewDir are compared against the png
+// next to them with the same name (create them with -update)
+func TestScreenRegression(t *testing.T) {
+	tests := append(screenTests, homebrewTests(t)...)
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := os.Stat(test.rom); err != nil {
+				t.Fatalf("rom not found at %s", test.rom)
+			}
+
+			got := runROMHeadless(t, test.rom, test.frames)
+
+			if *updateRefs {
+				writePNG(t, test.reference, got)
+				return
+			}
+
+			if _, err := os.Stat(test.reference); err != nil {
+				t.Fatalf("reference image not found at %s (run with -update to create it)", test.reference)
+			}
+
+			compareScreens(t, test.name, got, readPNG(t, test.reference))
+		})
+	}
+}
+
+// homebrewTests build the screen tests for the roms in the homebrew directory
+func homebrewTests(t *testing.T) []screenTest {
+	roms, err := filepath.Glob(filepath.Join(homebrewDir, "*.gb"))
+	if err != nil {
+		t.Fatalf("Failed to search for homebrew roms: %v", err)
+	}
+	if len(roms) == 0 {
+		t.Fatalf("No homebrew roms found in %s", homebrewDir)
+	}
+
+	var tests []screenTest
+	for _, rom := range roms {
+		name := strings.TrimSuffix(filepath.Base(rom), filepath.Ext(rom))
+		tests = append(tests, screenTest{
+			name:      name,
+			rom:       rom,
+			reference: strings.TrimSuffix(rom, filepath.Ext(rom)) + ".png",
+			frames:    homebrewFrames,
+		})
+	}
+
+	return tests
+}
+
+// newTestEmulator load the rom at romPath into a headless emulator, failing
+// the test if it's missing or doesn't load
+func newTestEmulator(t *testing.T, romPath string) *Emulator {
+	t.Helper()
+
+	rom, err := cartridge.ReadROMFile(romPath, cartridge.LoadOptions{})
+	if err != nil {
+		t.Fatalf("Failed to read rom: %v", err)
+	}
+
+	emu, err := NewHeadlessEmulator(rom)
+	if err != nil {
+		t.Fatalf("Failed to create emulator: %v", err)
+	}
+
+	return emu
+}
+
+// runROMHeadless run the rom at romPath for the given amount of frames and
+// return the final screen using the grey palette
+func runROMHeadless(t *testing.T, romPath string, frames int) *image.RGBA {
+	t.Helper()
+
+	emu := newTestEmulator(t, romPath)
+	emu.RunFrames(frames)
+	return emu.PPU.Screen.Image(ppu.GreyPalette)
+}
+
+// compareScreens compare got and want pixel by pixel, writing the output and a
+// diff image to failureDir if they don't match
+func compareScreens(t *testing.T, name string, got *image.RGBA, want image.Image) {
+	t.Helper()
+
+	if !got.Bounds().Eq(want.Bounds()) {
+		t.Fatalf("Image size mismatch: got %v, want %v", got.Bounds(), want.Bounds())
+	}
+
+	bounds := got.Bounds()
+	diff := image.NewRGBA(bounds)
+	mismatches := 0
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			wantCol := color.RGBAModel.Convert(want.At(x, y)).(color.RGBA)
+			if got.RGBAAt(x, y) != wantCol {
+				mismatches++
+				diff.SetRGBA(x, y, color.RGBA{R: 0xFF, A: 0xFF})
+				continue
+			}
+
+			// fade the matching pixels so the mismatches stand out
+			diff.SetRGBA(x, y, color.RGBA{
+				R: wantCol.R/4 + 0xBF,
+				G: wantCol.G/4 + 0xBF,
+				B: wantCol.B/4 + 0xBF,
+				A: 0xFF,
+			})
+		}
+	}
+
+	if mismatches == 0 {
+		return
+	}
+
+	actualPath := filepath.Join(failureDir, name+"-actual.png")
+	diffPath := filepath.Join(failureDir, name+"-diff.png")
+	writePNG(t, actualPath, got)
+	writePNG(t, diffPath, diff)
+
+	t.Errorf("%d pixels differ from the reference, output written to %s and diff to %s", mismatches, actualPath, diffPath)
+}
+
+// readPNG read and decode the png at path
+func readPNG(t *testing.T, path string) image.Image {
+	t.Helper()
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Failed to open image: %v", err)
+	}
+	defer file.Close()
+
+	img, err := png.Decode(file)
+	if err != nil {
+		t.Fatalf("Failed to decode %s: %v", path, err)
+	}
+
+	return img
+}
+
+// writePNG encode img as a png and write it to path, creating the directory if needed
+func writePNG(t *testing.T, path string, img image.Image) {
+	t.Helper()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		t.Fatalf("Failed to create directory for %s: %v", path, err)
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("Failed to create image: %v", err)
+	}
+	defer file.Close()
+
+	if err := png.Encode(file, img); err != nil {
+		t.Fatalf("Failed to encode %s: %v", path, err)
+	}
+}
@@ -0,0 +1,69 @@
+package emulator
+
+import (
+	"fmt"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/TheOrnyx/dmg-go/ppu"
+)
+
+var ScreenshotDirLoc string = "./Screenshots" // where screenshots taken with the hotkey are saved
+var ScreenshotScale int = 1                   // the integer scale screenshots are saved at
+
+// TakeScreenshot save the current frame to a timestamped png in ScreenshotDirLoc
+// using the renderers palette and return the path it was saved to
+func (e *Emulator) TakeScreenshot() (string, error) {
+	name := fmt.Sprintf("%s_%s.png", e.romTitle(), time.Now().Format("2006-01-02_15-04-05.000"))
+	path := filepath.Join(ScreenshotDirLoc, name)
+
+	err := SaveScreenshot(&e.PPU.Screen, e.Renderer.Palette(), ScreenshotScale, path)
+	if err != nil {
+		return "", err
+	}
+
+	return path, nil
+}
+
+// SaveScreenshot save the FinalScreen of screen as a png at path using the
+// given palette, scaled up by scale
+func SaveScreenshot(screen *ppu.Screen, palette ppu.Palette, scale int, path string) error {
+	img := scaleImage(screen.Image(palette), scale)
+
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return fmt.Errorf("Failed to create screenshot directory: %v", err)
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("Failed to create screenshot file: %v", err)
+	}
+	defer file.Close()
+
+	if err := png.Encode(file, img); err != nil {
+		return fmt.Errorf("Failed to encode screenshot: %v", err)
+	}
+
+	return nil
+}
+
+// scaleImage scale img up by an integer scale using nearest neighbour
+func scaleImage(img *image.RGBA, scale int) *image.RGBA {
+	if scale <= 1 {
+		return img
+	}
+
+	bounds := img.Bounds()
+	scaled := image.NewRGBA(image.Rect(0, 0, bounds.Dx()*scale, bounds.Dy()*scale))
+
+	for y := range scaled.Bounds().Dy() {
+		for x := range scaled.Bounds().Dx() {
+			scaled.SetRGBA(x, y, img.RGBAAt(x/scale, y/scale))
+		}
+	}
+
+	return scaled
+}
@@ -0,0 +1,41 @@
+package emulator
+
+import (
+	"io"
+	"log"
+
+	"github.com/TheOrnyx/dmg-go/serial"
+)
+
+// FeedSerial plug in a device that sends the game the bytes in the file at path
+func (e *Emulator) FeedSerial(path string) error {
+	feeder, err := serial.OpenFeeder(path)
+	if err != nil {
+		return err
+	}
+
+	e.Serial.Device = feeder
+	return nil
+}
+
+// LogSerial log every byte through the serial port to the file at path (or
+// stdout if it's "-"), in front of whatever is already plugged in
+func (e *Emulator) LogSerial(path string) error {
+	logger, err := serial.OpenLogger(path, e.Serial.Device)
+	if err != nil {
+		return err
+	}
+
+	e.Serial.Device = logger
+	return nil
+}
+
+// UnplugSerial close whatever is plugged into the serial port
+func (e *Emulator) UnplugSerial() {
+	if closer, ok := e.Serial.Device.(io.Closer); ok {
+		if err := closer.Close(); err != nil {
+			log.Println("Failed to close serial device:", err)
+		}
+	}
+	e.Serial.Device = nil
+}
@@ -0,0 +1,30 @@
+package emulator
+
+import "testing"
+
+// TestSerialDoubleSpeed check a transfer takes 8 bits of 512 cycles and raises
+// the serial interrupt. The serial port is clocked by the cpu so in double speed
+// it takes as many cpu cycles, which is half as long
+func TestSerialDoubleSpeed(t *testing.T) {
+	for _, doubleSpeed := range []bool{false, true} {
+		emu, err := NewHeadlessEmulator(make([]byte, 0x8000)) // nothing but NOPs
+		if err != nil {
+			t.Fatalf("Failed to create emulator: %v", err)
+		}
+		emu.Timer.SetDoubleSpeed(doubleSpeed)
+		emu.MMU.WriteByte(0xFF0F, 0x00)
+		emu.MMU.WriteByte(0xFF02, 0x81)
+
+		cycles := 0
+		for emu.MMU.ReadByte(0xFF02)&0x80 != 0 && cycles < 0x10000 {
+			cycles += emu.stepHardware()
+		}
+
+		if cycles != 8*512 {
+			t.Errorf("double speed %v: transfer took %d cpu cycles, want %d", doubleSpeed, cycles, 8*512)
+		}
+		if emu.MMU.ReadByte(0xFF0F)&0x08 == 0 {
+			t.Errorf("double speed %v: serial interrupt wasn't requested", doubleSpeed)
+		}
+	}
+}
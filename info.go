@@ -0,0 +1,90 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/TheOrnyx/dmg-go/cartridge"
+)
+
+// romInfo the header of a rom for the info subcommand, Error is set instead
+// of Header if the rom couldn't be read
+type romInfo struct {
+	Path   string            `json:"path"`
+	Header *cartridge.Header `json:"header,omitempty"`
+	Error  string            `json:"error,omitempty"`
+}
+
+// readInfo read the rom at path and decode its header
+func readInfo(path string, opts cartridge.LoadOptions) romInfo {
+	info := romInfo{Path: path}
+
+	rom, err := cartridge.ReadROMFile(path, opts)
+	if err != nil {
+		info.Error = fmt.Sprintf("Failed to read rom: %v", err)
+		return info
+	}
+
+	info.Header, err = cartridge.ParseHeader(rom)
+	if err != nil {
+		info.Error = err.Error()
+	}
+	return info
+}
+
+// runInfo the info subcommand, print the header of every rom given and return
+// the exit code, a bad rom is reported but doesn't stop the rest from being printed
+func runInfo(args []string) int {
+	flags := flag.NewFlagSet("info", flag.ExitOnError)
+	asJSON := flags.Bool("json", false, "print the headers as a JSON array, one entry per rom")
+	var opts cartridge.LoadOptions
+	flags.StringVar(&opts.Entry, "entry", "", "read `name` from zipped roms instead of the first .gb or .gbc")
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s info [--json] [--entry name] rom...\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return 2
+	}
+
+	infos := make([]romInfo, flags.NArg())
+	exitCode := 0
+	for i, path := range flags.Args() {
+		infos[i] = readInfo(path, opts)
+		if infos[i].Error != "" {
+			exitCode = 1
+		}
+	}
+
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(infos); err != nil {
+			fmt.Fprintln(os.Stderr, "Failed to encode info:", err)
+			return 1
+		}
+		return exitCode
+	}
+
+	for i, info := range infos {
+		if i > 0 {
+			fmt.Println()
+		}
+		if len(infos) > 1 {
+			fmt.Printf("%s:\n", info.Path)
+		}
+
+		if info.Error != "" {
+			fmt.Fprintf(os.Stderr, "%s: %s\n", info.Path, info.Error)
+			continue
+		}
+		fmt.Print(info.Header)
+	}
+
+	return exitCode
+}
@@ -20,8 +20,11 @@ const (
 type Joypad struct {
 	keys [2]byte // the two sets of keypressed (0 = dpad, 1 = buttons)
 	keyMode byte // which set of keys to use
+	players byte // the number of joypads connected by the SGB MLT_REQ command (0 or 1 = just one)
+	player byte // the joypad currently being read when multiple are connected
 	
 	requestInterrupt func(code byte) // the function pointer to request joypad interrupt
+	OnWrite func(data byte) // called with every write to the joypad register (used for SGB command packets)
 }
 
 // NewJoypad create a new joypad
@@ -116,19 +119,42 @@ func (j *Joypad) keyUp(key int)  {
 
 // Read read the joypad
 func (j *Joypad) Read() byte {
+	if j.player != 0 && j.keyMode != 0x30 { // the other SGB joypads never have anything pressed
+		return 0x0F | j.keyMode
+	}
+
 	switch j.keyMode {
 	case DpadMode:
 		return j.keys[0] | DpadMode
 	case ButtonMode:
 		return j.keys[1] | ButtonMode
+	case 0x30:
+		if j.players > 1 { // with multiple joypads the ID of the selected one is read instead
+			return 0x0F - j.player
+		}
+		return 0xF
 	default:
 		return 0xF
 	}
 	
 }
 
+// SetPlayers set the number of joypads connected through the SGB (1, 2 or 4)
+// Only the first joypad has any buttons pressed
+func (j *Joypad) SetPlayers(players byte) {
+	j.players = players
+	j.player = 0
+}
+
 // WriteData write to the joypad
 // NOTE - I assume this only ever writes to bit 4 and 5? 
 func (j *Joypad) WriteData(data byte)  {
+	if j.players > 1 && j.keyMode&DpadMode == 0 && data&DpadMode != 0 { // P15 going high selects the next joypad
+		j.player = (j.player + 1) % j.players
+	}
 	j.keyMode = data & 0x30
+
+	if j.OnWrite != nil {
+		j.OnWrite(data)
+	}
 }
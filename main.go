@@ -7,14 +7,24 @@ import (
 
 	"flag"
 
+	"github.com/TheOrnyx/dmg-go/config"
 	"github.com/TheOrnyx/dmg-go/debugger"
 	_ "github.com/TheOrnyx/dmg-go/debugger"
 	emu "github.com/TheOrnyx/dmg-go/emulator"
+	"github.com/TheOrnyx/dmg-go/ppu"
 	"github.com/TheOrnyx/dmg-go/window"
 	// "github.com/TheOrnyx/dmg-go/window"
 )
 
 var debugMode bool = false
+var screenshotFrame int // the frame to take a screenshot at before exiting (0 = disabled)
+var recordPath string   // where to record video to (empty = disabled)
+var configPath string   // the config file to load (empty = default location)
+var printConfig bool    // print the default config and exit
+var linkSpec string     // the link cable to connect, "listen :port" or "connect host:port" (empty = disabled)
+var usePrinter bool     // plug a Game Boy Printer into the serial port
+var serialFeed string   // a file to send to the game through the serial port (empty = disabled)
+var serialLog string    // where to log the serial traffic to, "-" for stdout (empty = disabled)
 const UsingSDL = true
 const WinScalar = 4 //the scalar used to scale up the gbc screen
 const WinWidth, WinHeight = 160 * WinScalar, 144 * WinScalar
@@ -25,23 +35,189 @@ func enableDebug(b string) error {
 	return nil
 }
 
+// screenshotScreen a headless screen that uses the config palettes, so the
+// screenshot matches what the window would show
+type screenshotScreen struct {
+	emu.HeadlessScreen
+}
+
+// Palette return the palette currently used to draw the screen
+func (s *screenshotScreen) Palette() ppu.Palette {
+	return window.ActivePalette()
+}
+
+// SetPalette switch to the palette called name
+func (s *screenshotScreen) SetPalette(name string) {
+	window.SelectPalette(name)
+}
+
+// screenshotAtFrame run the rom without a window for frames amount of
+// frames and save the screen to outPath
+func screenshotAtFrame(frames int, outPath, romPath string) error {
+	emulator, err := emu.NewEmulator(romPath, &screenshotScreen{})
+	if err != nil {
+		return err
+	}
+
+	if err := setupSerial(emulator); err != nil {
+		return err
+	}
+	defer emulator.UnplugSerial()
+
+	emulator.RunFrames(frames)
+	return emu.SaveScreenshot(&emulator.PPU.Screen, emulator.Renderer.Palette(), emu.ScreenshotScale, outPath)
+}
+
+// joinLinkArgs join "--link listen :port" into a single "--link=listen :port"
+// argument so the address isn't taken as the rom path
+func joinLinkArgs(args []string) []string {
+	joined := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		if (args[i] == "--link" || args[i] == "-link") && i+2 < len(args) &&
+			(args[i+1] == "listen" || args[i+1] == "connect") {
+			joined = append(joined, fmt.Sprintf("--link=%s %s", args[i+1], args[i+2]))
+			i += 2
+			continue
+		}
+		joined = append(joined, args[i])
+	}
+	return joined
+}
+
+// setupSerial plug the devices picked with the flags into the serial port
+func setupSerial(emulator *emu.Emulator) error {
+	if linkSpec != "" {
+		if err := emulator.StartLink(linkSpec); err != nil {
+			return fmt.Errorf("Error starting link cable: %v", err)
+		}
+	}
+
+	if usePrinter {
+		emulator.ConnectPrinter()
+	}
+
+	if serialFeed != "" {
+		if err := emulator.FeedSerial(serialFeed); err != nil {
+			return fmt.Errorf("Error starting serial feed: %v", err)
+		}
+	}
+
+	if serialLog != "" {
+		if err := emulator.LogSerial(serialLog); err != nil {
+			return fmt.Errorf("Error starting serial log: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// countSet count how many of flags are set
+func countSet(flags ...bool) int {
+	count := 0
+	for _, set := range flags {
+		if set {
+			count++
+		}
+	}
+	return count
+}
+
+// loadConfig load the config from configPath or the default location
+func loadConfig() (*config.Config, error) {
+	path := configPath
+	if path == "" {
+		var err error
+		path, err = config.DefaultPath()
+		if err != nil {
+			return config.Default(), nil // nowhere to look so just use the defaults
+		}
+	}
+
+	return config.Load(path)
+}
+
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "info" {
+		os.Exit(runInfo(os.Args[2:]))
+	}
+
 	flag.Usage = func() {
-		fmt.Fprintf(os.Stderr, "Usage of dmg-go: %s [flags] [rom path]", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Usage of dmg-go: %s [flags] [rom path] (.zip, .gz or - for stdin work too)\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "       %s [flags] --screenshot-at-frame N [out.png] [rom path]\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "       %s info [--json] [--entry name] rom...\n", os.Args[0])
 
 		flag.PrintDefaults()
 	}
 	
 	flag.BoolFunc("debug", "Use debug mode", enableDebug)
-	flag.Parse()
+	flag.IntVar(&screenshotFrame, "screenshot-at-frame", 0, "run without a window for `N` frames, save a screenshot to the path given before the rom and exit")
+	flag.IntVar(&emu.ScreenshotScale, "screenshot-scale", 1, "integer `scale` to save screenshots at")
+	flag.StringVar(&recordPath, "record-video", "", "record every frame to `out` (a .y4m file, otherwise a directory of PNGs)")
+	flag.StringVar(&emu.Model, "model", emu.ModelAuto, "the `model` to emulate: auto, dmg, cgb (colourises DMG games) or sgb")
+	flag.StringVar(&emu.CompatCombo, "compat-palette", "", "pick the colours for DMG games on the cgb model with a boot `combo` like left+b instead of by title")
+	flag.StringVar(&configPath, "config", "", "load the config from `path` instead of $XDG_CONFIG_HOME/dmg-go/config.json")
+	flag.BoolVar(&printConfig, "print-default-config", false, "print the default config and exit")
+	flag.StringVar(&linkSpec, "link", "", "connect a link cable to another dmg-go, either `listen :port or connect host:port`")
+	flag.BoolVar(&usePrinter, "printer", false, "plug in a Game Boy Printer, prints are saved as pngs in the save directory")
+	flag.StringVar(&emu.CameraImagePath, "camera-image", "", "the png or jpeg `image` the Pocket Camera takes photos of (a test pattern by default)")
+	flag.Func("patch", "apply the IPS, BPS or UPS patch in `file` to the rom, can be given more than once (by default rom.ips/.bps/.ups next to the rom is used)", func(path string) error {
+		emu.PatchPaths = append(emu.PatchPaths, path)
+		return nil
+	})
+	flag.IntVar(&emu.SaveBackups, "save-backups", emu.SaveBackups, "keep `N` older copies of each save file")
+	flag.DurationVar(&emu.AutosaveInterval, "autosave", emu.AutosaveInterval, "write the save file this often while the game is changing it (0 to only save on exit)")
+	flag.StringVar(&emu.ROMOptions.Entry, "entry", "", "load `name` from a zipped rom instead of the first .gb or .gbc in it")
+	flag.BoolVar(&emu.ROMOptions.Strict, "strict", false, "refuse to load roms with bad checksums or sizes instead of warning")
+	flag.StringVar(&serialFeed, "serial-feed", "", "send the bytes in `file` to the game through the serial port")
+	flag.StringVar(&serialLog, "serial-log", "", "log every byte through the serial port with timestamps to `file` (- for stdout)")
+	flag.CommandLine.Parse(joinLinkArgs(os.Args[1:]))
+
+	if countSet(linkSpec != "", usePrinter, serialFeed != "") > 1 {
+		log.Fatal("Only one of --link, --printer and --serial-feed can be used, there's only one serial port")
+	}
+
+	if printConfig {
+		if err := config.Default().Write(os.Stdout); err != nil {
+			log.Fatal("Failed to print config: ", err)
+		}
+		return
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	palettes, err := cfg.LoadPalettes()
+	if err != nil {
+		log.Fatal("Error in config palettes: ", err)
+	}
+	window.SetPalettes(palettes, cfg.Palette)
+
+	if screenshotFrame > 0 {
+		if flag.NArg() < 2 {
+			flag.Usage()
+			os.Exit(2)
+		}
+
+		if err := screenshotAtFrame(screenshotFrame, flag.Arg(0), flag.Arg(1)); err != nil {
+			log.Fatal("Failed to take screenshot: ", err)
+		}
+		return
+	}
 	
 	romPath := flag.Args()[0]
+	bindings, err := window.NewBindings(cfg)
+	if err != nil {
+		log.Fatal("Error in config key bindings: ", err)
+	}
+
 	var win window.Screen
 	
 	if debugMode {
-		win = window.CreateDebugWindow(WinWidth, WinHeight, WinScalar)
+		win = window.CreateDebugWindow(WinWidth, WinHeight, WinScalar, bindings)
 	} else {
-		win = window.InitSDLWindowSystem(WinWidth, WinHeight, WinScalar)
+		win = window.InitSDLWindowSystem(WinWidth, WinHeight, WinScalar, bindings)
 	} 
 	
 	emulator, err := emu.NewEmulator(romPath, win)
@@ -50,6 +226,17 @@ func main() {
 	}
 
 	defer emulator.CloseEmulator()
+
+	if err := setupSerial(emulator); err != nil {
+		log.Fatal(err)
+	}
+
+	if recordPath != "" {
+		emulator.Recorder, err = emu.NewVideoRecorder(recordPath)
+		if err != nil {
+			log.Fatal("Error starting video recording:", err)
+		}
+	}
 	
 	if debugMode {
 		debugger.DebugEmu(emulator)
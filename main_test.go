@@ -5,6 +5,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/TheOrnyx/dmg-go/config"
 	"github.com/TheOrnyx/dmg-go/emulator"
 	"github.com/TheOrnyx/dmg-go/window"
 )
@@ -13,7 +14,11 @@ import (
 func TestEmu(t *testing.T)  {
 	flag.Parse()
 	romPath := flag.Args()[0]
-	win := window.InitSDLWindowSystem(WinWidth, WinHeight, WinScalar)
+	bindings, err := window.NewBindings(config.Default())
+	if err != nil {
+		t.Fatalf("Failed to load the default bindings:%v", err)
+	}
+	win := window.InitSDLWindowSystem(WinWidth, WinHeight, WinScalar, bindings)
 
 	emulator, err := emulator.NewEmulator(romPath, win)
 	if err != nil {
@@ -0,0 +1,163 @@
+package mmu
+
+// hdma the CGB VRAM DMA registers (0xFF51 - 0xFF55)
+// General purpose DMA copies everything at once, HBlank DMA copies 16 bytes every HBlank
+type hdma struct {
+	source    uint16 // the address to copy from (HDMA1 - HDMA2)
+	dest      uint16 // the address in VRAM to copy to (HDMA3 - HDMA4)
+	remaining byte   // blocks of 16 bytes left to copy minus 1
+	active    bool   // whether an HBlank DMA is running
+	stall     int    // M-cycles the CPU is held up for by the blocks copied so far
+}
+
+// hdmaBlockCycles the M-cycles the CPU is stalled for while each block of 16
+// bytes is copied, twice as many in double speed as the copy takes the same time
+const hdmaBlockCycles = 8
+
+// CGB speed switch register bits (KEY1 0xFF4D)
+const (
+	key1Prepare     = 0x01 // set by the game before STOP to switch speed
+	key1DoubleSpeed = 0x80 // the current speed
+)
+
+// readCGB read the CGB only registers handled by the MMU
+// Returns false if addr isn't one of them
+func (mmu *MMU) readCGB(addr uint16) (byte, bool) {
+	switch addr {
+	case 0xFF4D: // KEY1
+		key1 := 0x7E | mmu.speedSwitch
+		if mmu.IO.TimerControl.DoubleSpeed() {
+			key1 |= key1DoubleSpeed
+		}
+		return key1, true
+
+	case 0xFF4F: // VBK
+		return 0xFE | mmu.PPU.VRAM.Bank, true
+
+	case 0xFF51, 0xFF52, 0xFF53, 0xFF54: // HDMA1-4 are write only
+		return 0xFF, true
+
+	case 0xFF55: // HDMA5
+		if !mmu.hdma.active {
+			return 0x80 | mmu.hdma.remaining, true
+		}
+		return mmu.hdma.remaining, true
+
+	case 0xFF68, 0xFF69, 0xFF6A, 0xFF6B: // BCPS, BCPD, OCPS, OCPD
+		return mmu.IO.LCD.ReadByte(addr), true
+
+	case 0xFF70: // SVBK
+		return 0xF8 | mmu.WRAM.Bank, true
+	}
+
+	return 0, false
+}
+
+// writeCGB write to the CGB only registers handled by the MMU
+// Returns false if addr isn't one of them
+func (mmu *MMU) writeCGB(addr uint16, data byte) bool {
+	switch addr {
+	case 0xFF4D: // KEY1
+		mmu.speedSwitch = data & key1Prepare
+
+	case 0xFF4F: // VBK
+		mmu.PPU.VRAM.Bank = data & 0x01
+
+	case 0xFF51: // HDMA1
+		mmu.hdma.source = uint16(data)<<8 | mmu.hdma.source&0x00F0
+
+	case 0xFF52: // HDMA2
+		mmu.hdma.source = mmu.hdma.source&0xFF00 | uint16(data&0xF0)
+
+	case 0xFF53: // HDMA3
+		mmu.hdma.dest = uint16(data&0x1F)<<8 | mmu.hdma.dest&0x00F0
+
+	case 0xFF54: // HDMA4
+		mmu.hdma.dest = mmu.hdma.dest&0x1F00 | uint16(data&0xF0)
+
+	case 0xFF55: // HDMA5
+		mmu.startHDMA(data)
+
+	case 0xFF68, 0xFF69, 0xFF6A, 0xFF6B: // BCPS, BCPD, OCPS, OCPD
+		mmu.IO.LCD.WriteByte(addr, data)
+
+	case 0xFF70: // SVBK
+		mmu.WRAM.Bank = data & 0x07
+
+	default:
+		return false
+	}
+
+	return true
+}
+
+// startHDMA start a VRAM DMA from a write to HDMA5
+// Bit 7 chooses HBlank DMA over general purpose DMA, writing it as 0 while
+// an HBlank DMA is running stops it instead
+func (mmu *MMU) startHDMA(data byte) {
+	if data&0x80 == 0 && mmu.hdma.active {
+		mmu.hdma.active = false // remaining is kept so HDMA5 shows how much was left
+		return
+	}
+
+	mmu.hdma.remaining = data & 0x7F
+	if data&0x80 == 0 {
+		for mmu.copyHDMABlock() {
+		}
+		return
+	}
+
+	mmu.hdma.active = true
+}
+
+// stepHDMA copy the next block of an HBlank DMA, called by the PPU at the start
+// of each HBlank. Nothing is copied while the LCD is off
+func (mmu *MMU) stepHDMA() {
+	if !mmu.hdma.active || !mmu.IO.LCD.LCDOn() {
+		return
+	}
+
+	if !mmu.copyHDMABlock() {
+		mmu.hdma.active = false
+	}
+}
+
+// copyHDMABlock copy 16 bytes from the source to VRAM and move both along
+// and stall the CPU for the time it takes
+// Returns false once the last block has been copied (remaining reads 0xFF)
+func (mmu *MMU) copyHDMABlock() bool {
+	for i := uint16(0); i < 0x10; i++ {
+		mmu.PPU.WriteByte(0x8000+(mmu.hdma.dest+i)&0x1FFF, mmu.ReadByte(mmu.hdma.source+i))
+	}
+	mmu.hdma.source += 0x10
+	mmu.hdma.dest = (mmu.hdma.dest + 0x10) & 0x1FF0
+
+	if mmu.IO.TimerControl.DoubleSpeed() {
+		mmu.hdma.stall += hdmaBlockCycles * 2
+	} else {
+		mmu.hdma.stall += hdmaBlockCycles
+	}
+
+	mmu.hdma.remaining--
+	return mmu.hdma.remaining != 0xFF
+}
+
+// TakeDMAStall return the M-cycles the CPU has to wait for the VRAM DMA
+// and clear them
+func (mmu *MMU) TakeDMAStall() int {
+	stall := mmu.hdma.stall
+	mmu.hdma.stall = 0
+	return stall
+}
+
+// SwitchSpeed switch between normal and double speed if the game asked for
+// it through KEY1, called when the CPU runs STOP. Returns whether it switched
+func (mmu *MMU) SwitchSpeed() bool {
+	if !mmu.CGBMode || mmu.speedSwitch&key1Prepare == 0 {
+		return false
+	}
+
+	mmu.speedSwitch = 0
+	mmu.IO.TimerControl.SetDoubleSpeed(!mmu.IO.TimerControl.DoubleSpeed())
+	return true
+}
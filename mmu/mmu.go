@@ -6,6 +6,7 @@ import (
 	"github.com/TheOrnyx/dmg-go/cartridge"
 	"github.com/TheOrnyx/dmg-go/joypad"
 	"github.com/TheOrnyx/dmg-go/ppu"
+	"github.com/TheOrnyx/dmg-go/serial"
 	"github.com/TheOrnyx/dmg-go/timer"
 )
 
@@ -20,33 +21,40 @@ import (
 const maxDebugArrSize = 100
 
 type WorkRam struct {
-	RAM [0x2000]byte
+	RAM  [0x8000]byte // 8 banks of 4KiB, banks 2-7 are only used in CGB mode
+	Bank byte         // the bank mapped to 0xD000 - 0xDFFF (SVBK 0xFF70), 0 selects bank 1
 }
 
 // ReadByte read byte from active bank in vram
 func (w *WorkRam) ReadByte(addr uint16) byte {
-	// bankAddr := addr & 0x0FFF // TODO - check whether to mask
-	return w.RAM[addr]
+	return w.RAM[w.bankAddr(addr)]
 }
 
 // WriteByte write given data to addr
 func (w *WorkRam) WriteByte(addr uint16, data uint8) {
-	w.RAM[addr] = data // TODO - check whether to mask
+	w.RAM[w.bankAddr(addr)] = data
+}
+
+// bankAddr get the index into RAM for addr (0x0000 - 0x1FFF) using the selected bank for the upper half
+func (w *WorkRam) bankAddr(addr uint16) uint16 {
+	if addr < 0x1000 {
+		return addr
+	}
+
+	bank := uint16(max(w.Bank&0x07, 1))
+	return bank*0x1000 + (addr & 0x0FFF)
 }
 
 // IO the struct for the IO registers in the MMU
 type IO struct {
 	Joypad *joypad.Joypad       // joypad input         				(0xFF00)
-	SerialTransfer [2]byte      // serial transfer						(0xFF01 - 0xFF02)
+	Serial         *serial.Serial // serial transfer					(0xFF01 - 0xFF02)
 	TimerControl   *timer.Timer // timer and divider					(0xFF04 - 0xFF07)
 	Audio          [23]byte     // Audio								(0xFF10 - 0xFF26)
 	Wave           [16]byte     // Wave Pattern							(0xFF30 - 0xFF3F)
 	LCD            *ppu.LCDReg  // LCD control and other stuff			(0xFF40 - 0xFF4B)
-	VramBankSel    byte         // CGB byte for swapping vram bank		(0xFF4F)
 	BootROMEnabled byte         // Set to non-zero to disable boot rom	(0xFF50)
-	VramDMA        [5]byte      // VRAM DMA for CGB						(0xFF51 - 0xFF55)
-	Palettes       [4]byte      // Background and OBJ Palettes in CGB	(0xFF68 - 0xFF6B)
-	WramBankSel    byte         // CGB work ram bank select				(0xFF70)
+	// The CGB registers (0xFF4D - 0xFF70) are handled by the MMU as they only exist in CGB mode
 }
 
 // ReadByte read and return byte in addr from the IO registers
@@ -56,7 +64,7 @@ func (io *IO) ReadByte(addr uint16) byte {
 		return io.Joypad.Read()
 
 	case addr >= 0xFF01 && addr <= 0xFF02: // serial transfer
-		return io.SerialTransfer[addr-0xFF01]
+		return io.Serial.Read(addr)
 
 	case addr >= 0xFF04 && addr <= 0xFF07: // Timer and divider
 		return io.TimerControl.Read(addr)
@@ -71,17 +79,9 @@ func (io *IO) ReadByte(addr uint16) byte {
 		// }
 		return io.LCD.ReadByte(addr)
 
-	case addr == 0xFF4F: // Vram Bank Select (CGB)
-
 	case addr == 0xFF50: // Boot ROM
 		return io.BootROMEnabled
 
-	case addr >= 0xFF51 && addr <= 0xFF55: // VRAM DMA (CGB)
-
-	case addr >= 0xFF68 && addr <= 0xFF6B: // Palettes (CGB)
-
-	case addr == 0xFF70: // Wram bank Select (CGB)
-
 	}
 
 	return 0
@@ -95,7 +95,7 @@ func (io *IO) WriteByte(addr uint16, data byte) {
 		io.Joypad.WriteData(data)
 
 	case addr >= 0xFF01 && addr <= 0xFF02: // serial transfer
-		io.SerialTransfer[addr-0xFF01] = data
+		io.Serial.Write(addr, data)
 
 	case addr >= 0xFF04 && addr <= 0xFF07: // Timer and divider
 		io.TimerControl.Write(addr, data)
@@ -107,17 +107,9 @@ func (io *IO) WriteByte(addr uint16, data byte) {
 	case addr >= 0xFF40 && addr <= 0xFF4B: // LCD
 		io.LCD.WriteByte(addr, data)
 
-	case addr == 0xFF4F: // Vram Bank Select (CGB)
-
 	case addr == 0xFF50: // Boot ROM
 		io.BootROMEnabled = data
 
-	case addr >= 0xFF51 && addr <= 0xFF55: // VRAM DMA (CGB)
-
-	case addr >= 0xFF68 && addr <= 0xFF6B: // Palettes (CGB)
-
-	case addr == 0xFF70: // Wram bank Select (CGB)
-
 	}
 }
 
@@ -133,10 +125,13 @@ type MMU struct {
 	Cart             *cartridge.Cartridge
 	DebugMode        bool     // whether or not to record debug information
 	DebugRecords     []string // the debug information for read and write operations
+	CGBMode          bool     // whether the CGB registers and banks are enabled
+	hdma             hdma     // the CGB VRAM DMA
+	speedSwitch      byte     // the prepare bit of KEY1
 }
 
 // NewMMU create and return a new MMU
-func NewMMU(cart *cartridge.Cartridge, timer *timer.Timer, ppu *ppu.PPU, joypad *joypad.Joypad) *MMU {
+func NewMMU(cart *cartridge.Cartridge, timer *timer.Timer, ppu *ppu.PPU, joypad *joypad.Joypad, serial *serial.Serial) *MMU {
 	newMMU := new(MMU)
 	newMMU.Cart = cart
 	newMMU.PPU = ppu
@@ -144,10 +139,20 @@ func NewMMU(cart *cartridge.Cartridge, timer *timer.Timer, ppu *ppu.PPU, joypad
 	newMMU.IO.LCD = &ppu.LCD
 	newMMU.IO.BootROMEnabled = 1
 	newMMU.IO.Joypad = joypad
+	newMMU.IO.Serial = serial
 	newMMU.DebugMode = false // TODO - change later
+	newMMU.hdma.remaining = 0xFF // no DMA has run so HDMA5 reads 0xFF
+	ppu.OnHBlank = newMMU.stepHDMA
 	return newMMU
 }
 
+// EnableCGB turn on the CGB registers, VRAM/WRAM banks and CGB rendering
+func (mmu *MMU) EnableCGB() {
+	mmu.CGBMode = true
+	mmu.PPU.CGBMode = true
+	mmu.IO.Serial.CGBMode = true
+}
+
 // ReadByte read and return the byte located at address addr
 // TODO - finish and check
 func (mmu *MMU) ReadByte(addr uint16) byte {	
@@ -169,10 +174,10 @@ func (mmu *MMU) ReadByte(addr uint16) byte {
 		mmu.addReadToDebug(addr, data, "External Cart RAM")
 		return data
 
-	case addr >= 0xC000 && addr <= 0xDFFF: // first work ram bank
+	case addr >= 0xC000 && addr <= 0xDFFF: // work ram (0xD000 - 0xDFFF is switchable in CGB mode)
 		newAddr := addr - 0xC000
 		data := mmu.WRAM.ReadByte(newAddr)
-		mmu.addReadToDebug(addr, data, "WRAM bank 0")
+		mmu.addReadToDebug(addr, data, "WRAM")
 		return data
 
 		// Ignoring the 0xE000 -> 0xFDFF - nintendo says not allowed >:(
@@ -193,6 +198,13 @@ func (mmu *MMU) ReadByte(addr uint16) byte {
 		// ignore 0xFEA0 -> 0xFEFF - nintendo says not allowed again
 
 	case addr >= 0xFF00 && addr <= 0xFF7F: // I/O registers
+		if mmu.CGBMode {
+			if data, found := mmu.readCGB(addr); found {
+				mmu.addReadToDebug(addr, data, "CGB I/O")
+				return data
+			}
+		}
+
 		data := mmu.IO.ReadByte(addr)
 		mmu.addReadToDebug(addr, data, "I/O")
 		return data
@@ -213,7 +225,9 @@ func (mmu *MMU) ReadByte(addr uint16) byte {
 func (mmu *MMU) WriteByte(addr uint16, data byte) {
 	switch {
 	case addr >= 0x0000 && addr <= 0x7FFF: // Write to from Cart
-		mmu.Cart.MBC.WriteByte(addr, data)
+		if mmu.Cart.MBC.WriteByte(addr, data) { // MBC6 flash is written here
+			mmu.Cart.RAMDirty = true
+		}
 		mmu.addWriteToDebug(addr, data, "Cart")
 
 	case addr >= 0x8000 && addr <= 0x9FFF: // Video ram
@@ -222,7 +236,9 @@ func (mmu *MMU) WriteByte(addr uint16, data byte) {
 
 	case addr >= 0xA000 && addr <= 0xBFFF: // External ram on cart
 		// newAddr := addr - 0xA000
-		mmu.Cart.MBC.WriteByte(addr, data)
+		if mmu.Cart.MBC.WriteByte(addr, data) { // only when the ram is enabled and the byte changed
+			mmu.Cart.RAMDirty = true
+		}
 		mmu.addWriteToDebug(addr, data, "External Cart RAM")
 
 	case addr >= 0xC000 && addr <= 0xDFFF: // work ram
@@ -248,6 +264,11 @@ func (mmu *MMU) WriteByte(addr uint16, data byte) {
 		mmu.addWriteToDebug(addr, data, "IEF")
 
 	case addr >= 0xFF00 && addr <= 0xFF7F: // I/O registers
+		if mmu.CGBMode && mmu.writeCGB(addr, data) {
+			mmu.addWriteToDebug(addr, data, "CGB I/O")
+			return
+		}
+
 		mmu.IO.WriteByte(addr, data)
 		mmu.addWriteToDebug(addr, data, "I/O")
 		
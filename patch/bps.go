@@ -0,0 +1,98 @@
+package patch
+
+// the BPS actions, the lower 2 bits of each action number
+const (
+	bpsSourceRead = iota // copy from the rom at the same offset
+	bpsTargetRead        // copy bytes out of the patch
+	bpsSourceCopy        // copy from anywhere in the rom
+	bpsTargetCopy        // copy from earlier in the patched rom
+)
+
+// applyBPS apply a BPS patch. The patched rom is built from actions that copy from
+// the rom, the patch or what's been written already. The rom and patched rom crc32s
+// are checked against the footer
+func applyBPS(rom, patch []byte) ([]byte, error) {
+	if len(patch) < len(bpsMagic)+footerSize {
+		return nil, &CorruptError{Format: "BPS", Reason: "too short"}
+	}
+	target, err := checkFooter(rom, patch)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &reader{format: "BPS", data: patch, pos: len(bpsMagic), end: len(patch) - footerSize}
+	sourceSize := r.readNumber()
+	targetSize := r.readNumber()
+	r.readBytes(r.readNumber()) // skip the metadata
+	if r.err != nil {
+		return nil, r.err
+	}
+	if sourceSize != len(rom) {
+		r.fail("the rom is the wrong size")
+		return nil, r.err
+	}
+	if targetSize > 0x10000000 {
+		r.fail("target size too large")
+		return nil, r.err
+	}
+
+	out := make([]byte, targetSize)
+	outPos, sourceRel, targetRel := 0, 0, 0
+	for !r.done() {
+		action := r.readNumber()
+		length := action>>2 + 1
+		if length > len(out)-outPos {
+			r.fail("writes past the end of the patched rom")
+			break
+		}
+
+		switch action & 0x03 {
+		case bpsSourceRead:
+			if outPos+length > len(rom) {
+				r.fail("reads past the end of the rom")
+				break
+			}
+			copy(out[outPos:], rom[outPos:outPos+length])
+
+		case bpsTargetRead:
+			copy(out[outPos:], r.readBytes(length))
+
+		case bpsSourceCopy:
+			sourceRel += signed(r.readNumber())
+			if sourceRel < 0 || sourceRel+length > len(rom) {
+				r.fail("copies from outside the rom")
+				break
+			}
+			copy(out[outPos:], rom[sourceRel:sourceRel+length])
+			sourceRel += length
+
+		case bpsTargetCopy:
+			targetRel += signed(r.readNumber())
+			if targetRel < 0 || targetRel >= outPos {
+				r.fail("copies from outside the patched rom")
+				break
+			}
+			for i := 0; i < length; i++ { // byte at a time, the copy can overlap what it writes
+				out[outPos+i] = out[targetRel]
+				targetRel++
+			}
+		}
+		outPos += length
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	if err := checkTarget(out, target); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// signed decode a BPS relative offset, bit 0 is the sign
+func signed(n int) int {
+	if n&1 != 0 {
+		return -(n >> 1)
+	}
+	return n >> 1
+}
@@ -0,0 +1,65 @@
+package patch
+
+// ipsEOF the offset that marks the end of an IPS patch ("EOF")
+const ipsEOF = 0x454F46
+
+// applyIPS apply an IPS patch. Records write bytes (or a run of one byte) at an
+// offset, the rom grows if they write past its end and an optional size after the
+// end marker truncates it. IPS has no checksums
+func applyIPS(rom, patch []byte) ([]byte, error) {
+	out := append([]byte(nil), rom...)
+	r := &reader{format: "IPS", data: patch, pos: len(ipsMagic), end: len(patch)}
+
+	for !r.done() {
+		offset := r.readBytes(3)
+		if offset == nil {
+			break
+		}
+		addr := int(offset[0])<<16 | int(offset[1])<<8 | int(offset[2])
+		if addr == ipsEOF {
+			if truncate := r.readBytes(3); r.err == nil && len(truncate) == 3 {
+				size := int(truncate[0])<<16 | int(truncate[1])<<8 | int(truncate[2])
+				if size < len(out) {
+					out = out[:size]
+				}
+			} else {
+				r.err = nil // there's no truncate size, that's fine
+			}
+			return out, nil
+		}
+
+		size := r.readBytes(2)
+		if size == nil {
+			break
+		}
+		length := int(size[0])<<8 | int(size[1])
+
+		var data []byte
+		if length == 0 { // a run of the same byte
+			run := r.readBytes(2)
+			value := r.readByte()
+			if r.err != nil {
+				break
+			}
+			data = make([]byte, int(run[0])<<8|int(run[1]))
+			for i := range data {
+				data[i] = value
+			}
+		} else {
+			data = r.readBytes(length)
+		}
+		if r.err != nil {
+			break
+		}
+
+		if end := addr + len(data); end > len(out) {
+			out = append(out, make([]byte, end-len(out))...)
+		}
+		copy(out[addr:], data)
+	}
+
+	if r.err == nil {
+		r.fail("missing EOF marker")
+	}
+	return nil, r.err
+}
@@ -0,0 +1,159 @@
+// Package patch applies IPS, BPS and UPS rom patches in memory
+package patch
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+)
+
+// Extensions the file extensions of the supported patch formats
+var Extensions = []string{".ips", ".bps", ".ups"}
+
+var (
+	ipsMagic = []byte("PATCH")
+	bpsMagic = []byte("BPS1")
+	upsMagic = []byte("UPS1")
+)
+
+// footerSize the size of the source, target and patch crc32s at the end of BPS and UPS patches
+const footerSize = 12
+
+// ChecksumError a crc32 in a BPS or UPS patch doesn't match
+type ChecksumError struct {
+	Kind string // "source", "target" or "patch"
+	Want uint32 // the crc32 in the patch
+	Got  uint32 // the crc32 of the data
+}
+
+func (e *ChecksumError) Error() string {
+	switch e.Kind {
+	case "source":
+		return fmt.Sprintf("Patch is for a different rom, it wants crc32 %08X but the rom is %08X", e.Want, e.Got)
+	case "target":
+		return fmt.Sprintf("Patched rom has crc32 %08X but the patch says it should be %08X", e.Got, e.Want)
+	}
+	return fmt.Sprintf("Patch is damaged, its crc32 is %08X but it should be %08X", e.Got, e.Want)
+}
+
+// CorruptError the patch can't be parsed
+type CorruptError struct {
+	Format string
+	Reason string
+}
+
+func (e *CorruptError) Error() string {
+	return fmt.Sprintf("Corrupt %s patch: %s", e.Format, e.Reason)
+}
+
+// Format get the format of patch from its header, "" if it isn't a known format
+func Format(patch []byte) string {
+	switch {
+	case bytes.HasPrefix(patch, ipsMagic):
+		return "IPS"
+	case bytes.HasPrefix(patch, bpsMagic):
+		return "BPS"
+	case bytes.HasPrefix(patch, upsMagic):
+		return "UPS"
+	}
+	return ""
+}
+
+// Apply apply patch to rom and return the patched rom, rom isn't changed
+func Apply(rom, patch []byte) ([]byte, error) {
+	switch Format(patch) {
+	case "IPS":
+		return applyIPS(rom, patch)
+	case "BPS":
+		return applyBPS(rom, patch)
+	case "UPS":
+		return applyUPS(rom, patch)
+	}
+	return nil, fmt.Errorf("Unknown patch format")
+}
+
+// checkFooter check the patch crc32 and the source crc32 in the footer of a BPS or UPS
+// patch, the target crc32 is returned to check once it's applied
+func checkFooter(rom, patch []byte) (uint32, error) {
+	footer := patch[len(patch)-footerSize:]
+	source := binary.LittleEndian.Uint32(footer[0:])
+	target := binary.LittleEndian.Uint32(footer[4:])
+	self := binary.LittleEndian.Uint32(footer[8:])
+
+	if got := crc32.ChecksumIEEE(patch[:len(patch)-4]); got != self {
+		return 0, &ChecksumError{Kind: "patch", Want: self, Got: got}
+	}
+	if got := crc32.ChecksumIEEE(rom); got != source {
+		return 0, &ChecksumError{Kind: "source", Want: source, Got: got}
+	}
+	return target, nil
+}
+
+// checkTarget check the patched rom matches the target crc32
+func checkTarget(out []byte, want uint32) error {
+	if got := crc32.ChecksumIEEE(out); got != want {
+		return &ChecksumError{Kind: "target", Want: want, Got: got}
+	}
+	return nil
+}
+
+// reader reads the parts of a patch, every read past the end sets err instead of panicking
+type reader struct {
+	format string
+	data   []byte
+	pos    int
+	end    int // where the patch data stops (before the footer)
+	err    error
+}
+
+// fail record the first problem with the patch
+func (r *reader) fail(reason string) {
+	if r.err == nil {
+		r.err = &CorruptError{Format: r.format, Reason: reason}
+	}
+}
+
+// done whether everything before the footer has been read
+func (r *reader) done() bool {
+	return r.pos >= r.end || r.err != nil
+}
+
+// readBytes read n bytes
+func (r *reader) readBytes(n int) []byte {
+	if n < 0 || n > r.end-r.pos {
+		r.fail("unexpected end of patch")
+		r.pos = r.end
+		return nil
+	}
+	r.pos += n
+	return r.data[r.pos-n : r.pos]
+}
+
+// readByte read one byte
+func (r *reader) readByte() byte {
+	if b := r.readBytes(1); b != nil {
+		return b[0]
+	}
+	return 0
+}
+
+// readNumber read a BPS/UPS variable length number, 7 bits at a time with the top bit
+// marking the last byte and each continuation adding one to the next
+func (r *reader) readNumber() int {
+	value, shift := 0, 1
+	for !r.done() {
+		b := r.readByte()
+		value += int(b&0x7F) * shift
+		if b&0x80 != 0 {
+			return value
+		}
+		shift <<= 7
+		value += shift
+		if shift > 1<<42 { // bigger than any rom
+			r.fail("number too large")
+		}
+	}
+	r.fail("unexpected end of patch")
+	return 0
+}
@@ -0,0 +1,97 @@
+package patch
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"testing"
+)
+
+// encodeNumber encode n as a BPS/UPS variable length number
+func encodeNumber(n int) []byte {
+	var out []byte
+	for {
+		b := byte(n & 0x7F)
+		n >>= 7
+		if n == 0 {
+			return append(out, b|0x80)
+		}
+		out = append(out, b)
+		n--
+	}
+}
+
+// withFooter add the crc32 footer to a BPS or UPS patch body
+func withFooter(body, rom, target []byte) []byte {
+	patch := append([]byte(nil), body...)
+	patch = binary.LittleEndian.AppendUint32(patch, crc32.ChecksumIEEE(rom))
+	patch = binary.LittleEndian.AppendUint32(patch, crc32.ChecksumIEEE(target))
+	return binary.LittleEndian.AppendUint32(patch, crc32.ChecksumIEEE(patch))
+}
+
+func TestApply(t *testing.T) {
+	rom := []byte("ABCDEFGH")
+
+	ips := []byte("PATCH")
+	ips = append(ips, 0x00, 0x00, 0x01, 0x00, 0x02, 'x', 'y')        // "xy" at 1
+	ips = append(ips, 0x00, 0x00, 0x08, 0x00, 0x00, 0x00, 0x03, 'z') // "zzz" at 8, growing the rom
+	ips = append(ips, 'E', 'O', 'F', 0x00, 0x00, 0x0A)               // truncate to 10
+
+	upsWant := []byte("AbCDEFGHI")
+	ups := []byte("UPS1")
+	ups = append(ups, encodeNumber(len(rom))...)
+	ups = append(ups, encodeNumber(len(upsWant))...)
+	ups = append(ups, encodeNumber(1)...)
+	ups = append(ups, 'B'^'b', 0x00)
+	ups = append(ups, encodeNumber(5)...) // the 0 after each hunk moves on a byte too
+	ups = append(ups, 'I', 0x00)
+	ups = withFooter(ups, rom, upsWant)
+
+	bpsWant := []byte("ABCDhiABhiAB")
+	bps := []byte("BPS1")
+	bps = append(bps, encodeNumber(len(rom))...)
+	bps = append(bps, encodeNumber(len(bpsWant))...)
+	bps = append(bps, encodeNumber(0)...)                      // no metadata
+	bps = append(bps, encodeNumber((4-1)<<2|bpsSourceRead)...) // ABCD
+	bps = append(bps, encodeNumber((2-1)<<2|bpsTargetRead)...) // hi
+	bps = append(bps, 'h', 'i')
+	bps = append(bps, encodeNumber((2-1)<<2|bpsSourceCopy)...) // AB
+	bps = append(bps, encodeNumber(0)...)
+	bps = append(bps, encodeNumber((4-1)<<2|bpsTargetCopy)...) // hiAB
+	bps = append(bps, encodeNumber(4<<1)...)
+	bps = withFooter(bps, rom, bpsWant)
+
+	tests := []struct {
+		name  string
+		patch []byte
+		want  []byte
+	}{
+		{"IPS", ips, []byte("AxyDEFGHzz")},
+		{"UPS", ups, upsWant},
+		{"BPS", bps, bpsWant},
+	}
+
+	for _, test := range tests {
+		got, err := Apply(rom, test.patch)
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+		} else if !bytes.Equal(got, test.want) {
+			t.Errorf("%s: got %q, want %q", test.name, got, test.want)
+		}
+	}
+
+	if string(rom) != "ABCDEFGH" {
+		t.Errorf("the rom was changed to %q", rom)
+	}
+
+	var checksumErr *ChecksumError
+	if _, err := Apply([]byte("ABCDEFGX"), bps); !errors.As(err, &checksumErr) || checksumErr.Kind != "source" {
+		t.Errorf("patching the wrong rom gave %v, want a source checksum error", err)
+	}
+
+	var corruptErr *CorruptError
+	if _, err := Apply(rom, ips[:len(ips)-6]); !errors.As(err, &corruptErr) {
+		t.Errorf("IPS without an EOF marker gave %v, want a corrupt patch error", err)
+	}
+}
@@ -0,0 +1,54 @@
+package patch
+
+// applyUPS apply a UPS patch. Hunks skip ahead then xor bytes into the rom until a
+// 0 byte, the rom and patched rom crc32s are checked against the footer
+func applyUPS(rom, patch []byte) ([]byte, error) {
+	if len(patch) < len(upsMagic)+footerSize {
+		return nil, &CorruptError{Format: "UPS", Reason: "too short"}
+	}
+	target, err := checkFooter(rom, patch)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &reader{format: "UPS", data: patch, pos: len(upsMagic), end: len(patch) - footerSize}
+	sourceSize := r.readNumber()
+	targetSize := r.readNumber()
+	if r.err != nil {
+		return nil, r.err
+	}
+	if sourceSize != len(rom) {
+		r.fail("the rom is the wrong size")
+		return nil, r.err
+	}
+	if targetSize > 0x10000000 {
+		r.fail("target size too large")
+		return nil, r.err
+	}
+
+	out := make([]byte, targetSize)
+	copy(out, rom)
+
+	pos := 0
+	for !r.done() {
+		pos += r.readNumber()
+		for !r.done() {
+			b := r.readByte()
+			if pos < len(out) {
+				out[pos] ^= b
+			}
+			pos++
+			if b == 0 {
+				break
+			}
+		}
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	if err := checkTarget(out, target); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
@@ -0,0 +1,56 @@
+package ppu
+
+import "image/color"
+
+// ColorPalettes the CGB colour palette RAM for either the background or the
+// objects. Holds 8 palettes of 4 colours, each stored as a little endian
+// 15-bit colour (bits 0-4 red, 5-9 green, 10-14 blue)
+type ColorPalettes struct {
+	RAM           [64]byte
+	Index         byte // the byte in RAM the data register accesses (BCPS/OCPS bits 0-5)
+	AutoIncrement bool // whether writing to the data register moves Index along (BCPS/OCPS bit 7)
+}
+
+// readSpec read the specification register (BCPS/OCPS)
+func (c *ColorPalettes) readSpec() byte {
+	spec := c.Index | 0x40 // bit 6 is unused and always reads 1
+	if c.AutoIncrement {
+		spec |= 0x80
+	}
+	return spec
+}
+
+// writeSpec write the specification register (BCPS/OCPS)
+func (c *ColorPalettes) writeSpec(data byte) {
+	c.Index = data & 0x3F
+	c.AutoIncrement = data&0x80 != 0
+}
+
+// readData read the palette byte at Index (BCPD/OCPD)
+func (c *ColorPalettes) readData() byte {
+	return c.RAM[c.Index]
+}
+
+// writeData write the palette byte at Index and move along if auto increment is set (BCPD/OCPD)
+func (c *ColorPalettes) writeData(data byte) {
+	c.RAM[c.Index] = data
+	if c.AutoIncrement {
+		c.Index = (c.Index + 1) & 0x3F
+	}
+}
+
+// color get the 15-bit colour for colour index of palette
+func (c *ColorPalettes) color(palette, index byte) uint16 {
+	i := (palette&0x07)*8 + (index&0x03)*2
+	return uint16(c.RAM[i]) | uint16(c.RAM[i+1])<<8
+}
+
+// RGB15ToRGBA convert a 15-bit CGB colour to RGBA, scaling each 5 bit channel up to 8 bits
+func RGB15ToRGBA(rgb uint16) color.RGBA {
+	scale := func(c uint16) uint8 {
+		c &= 0x1F
+		return uint8(c<<3 | c>>2)
+	}
+
+	return color.RGBA{R: scale(rgb), G: scale(rgb >> 5), B: scale(rgb >> 10), A: 0xFF}
+}
@@ -0,0 +1,231 @@
+package ppu
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CompatPalette the colours the CGB boot rom loads for a DMG game, as 15-bit colours
+// BG goes into BG palette 0, OBJ0 and OBJ1 into object palettes 0 and 1
+type CompatPalette struct {
+	BG, OBJ0, OBJ1 [4]uint16
+}
+
+// rgb convert 4 24-bit colours (0xRRGGBB) to 15-bit colours
+func rgb(c0, c1, c2, c3 uint32) [4]uint16 {
+	var colors [4]uint16
+	for i, c := range [4]uint32{c0, c1, c2, c3} {
+		r, g, b := uint16(c>>19)&0x1F, uint16(c>>11)&0x1F, uint16(c>>3)&0x1F
+		colors[i] = r | g<<5 | b<<10
+	}
+	return colors
+}
+
+var (
+	compatRed       = rgb(0xFFFFFF, 0xFF8484, 0x943A3A, 0x000000)
+	compatGreen     = rgb(0xFFFFFF, 0x7BFF31, 0x008400, 0x000000)
+	compatBlue      = rgb(0xFFFFFF, 0x63A5FF, 0x0000FF, 0x000000)
+	compatBrown     = rgb(0xFFFFFF, 0xFFAD63, 0x843100, 0x000000)
+	compatYellowRed = rgb(0xFFFFFF, 0xFFFF00, 0xFF0000, 0x000000)
+)
+
+// samePalette a compat palette using colors for the BG and both object palettes
+func samePalette(colors [4]uint16) CompatPalette {
+	return CompatPalette{BG: colors, OBJ0: colors, OBJ1: colors}
+}
+
+// DefaultCompatPalette used for games not made by Nintendo or missing from the title table
+var DefaultCompatPalette = compatCombo(0)
+
+// CompatComboPalettes the palettes picked by holding a direction (and optionally A or B)
+// while the CGB boot logo is showing, these override the title based palette
+var CompatComboPalettes = map[string]CompatPalette{
+	"up":      samePalette(compatBrown),
+	"up+a":    {BG: compatRed, OBJ0: compatGreen, OBJ1: compatBlue},
+	"up+b":    samePalette(rgb(0xFFE6C5, 0xCE9C84, 0x846B29, 0x5A3108)),
+	"left":    {BG: compatBlue, OBJ0: compatRed, OBJ1: compatGreen},
+	"left+a":  {BG: rgb(0xFFFFFF, 0x8C8CDE, 0x52528C, 0x000000), OBJ0: compatRed, OBJ1: compatBrown},
+	"left+b":  samePalette(rgb(0xFFFFFF, 0xA5A5A5, 0x525252, 0x000000)),
+	"down":    samePalette(rgb(0xFFFFA5, 0xFF9494, 0x9494FF, 0x000000)),
+	"down+a":  samePalette(compatYellowRed),
+	"down+b":  {BG: rgb(0xFFFFFF, 0xFFFF00, 0x7B4A00, 0x000000), OBJ0: compatBlue, OBJ1: compatGreen},
+	"right":   DefaultCompatPalette,
+	"right+a": samePalette(rgb(0xFFFFFF, 0x52FF00, 0xFF4200, 0x000000)),
+	"right+b": samePalette(rgb(0x000000, 0x008484, 0xFFDE00, 0xFFFFFF)),
+}
+
+// compatChecksums the title checksums in the boot roms table, the first entry
+// is the default palette. Titles from compatFirstDuplicate on share their
+// checksum with another title so the 4th title letter has to match too
+var compatChecksums = [...]byte{
+	0x00, 0x88, 0x16, 0x36, 0xD1, 0xDB, 0xF2, 0x3C, 0x8C, 0x92, 0x3D, 0x5C, 0x58, 0xC9, 0x3E, 0x70,
+	0x1D, 0x59, 0x69, 0x19, 0x35, 0xA8, 0x14, 0xAA, 0x75, 0x95, 0x99, 0x34, 0x6F, 0x15, 0xFF, 0x97,
+	0x4B, 0x90, 0x17, 0x10, 0x39, 0xF7, 0xF6, 0xA2, 0x49, 0x4E, 0x43, 0x68, 0xE0, 0x8B, 0xF0, 0xCE,
+	0x0C, 0x29, 0xE8, 0xB7, 0x86, 0x9A, 0x52, 0x01, 0x9D, 0x71, 0x9C, 0xBD, 0x5D, 0x6D, 0x67, 0x3F,
+	0x6B, 0xB3, 0x46, 0x28, 0xA5, 0xC6, 0xD3, 0x27, 0x61, 0x18, 0x66, 0x6A, 0xBF, 0x0D, 0xF4, 0xB3,
+	0x46, 0x28, 0xA5, 0xC6, 0xD3, 0x27, 0x61, 0x18, 0x66, 0x6A, 0xBF, 0x0D, 0xF4, 0xB3,
+}
+
+// compatFirstDuplicate the index of the first checksum that needs its letter checked
+const compatFirstDuplicate = len(compatChecksums) - len(compatLetters)
+
+// compatLetters the 4th title letter for each checksum from compatFirstDuplicate on
+const compatLetters = "BEFAARBEKEK R-URAR INAILICE R"
+
+// compatPaletteIndex the entry in compatCombos for each checksum. Bit 7 is a flag
+// the boot rom uses for something other than the colours so it's ignored
+var compatPaletteIndex = [...]byte{
+	0x00, 0x04, 0x05, 0x23, 0x22, 0x03, 0x1F, 0x0F, 0x0A, 0x05, 0x13, 0x24, 0x87, 0x25, 0x1E, 0x2C,
+	0x15, 0x20, 0x1F, 0x14, 0x05, 0x21, 0x0D, 0x0E, 0x05, 0x1D, 0x05, 0x12, 0x09, 0x03, 0x02, 0x1A,
+	0x19, 0x19, 0x29, 0x2A, 0x1A, 0x2D, 0x2A, 0x2D, 0x24, 0x26, 0x9A, 0x2A, 0x1E, 0x29, 0x22, 0x22,
+	0x05, 0x2A, 0x06, 0x05, 0x21, 0x19, 0x2A, 0x2A, 0x28, 0x02, 0x10, 0x19, 0x2A, 0x2A, 0x05, 0x00,
+	0x27, 0x24, 0x16, 0x19, 0x06, 0x20, 0x0C, 0x24, 0x0B, 0x27, 0x12, 0x27, 0x18, 0x1F, 0x32, 0x11,
+	0x2E, 0x06, 0x1B, 0x00, 0x2F, 0x29, 0x29, 0x00, 0x00, 0x13, 0x22, 0x17, 0x12, 0x1D,
+}
+
+// compatCombos the OBJ0, OBJ1 and BG colours of each palette the boot rom can
+// pick, as the index of the first of their 4 colours in compatColors
+var compatCombos = [...][3]byte{
+	{16, 16, 116},
+	{72, 72, 72},
+	{80, 80, 80},
+	{96, 96, 96},
+	{36, 36, 36},
+	{0, 0, 0},
+	{108, 108, 108},
+	{20, 20, 20},
+	{48, 48, 48},
+	{104, 104, 104},
+	{64, 32, 32},
+	{16, 112, 112},
+	{16, 8, 8},
+	{12, 16, 16},
+	{16, 116, 116},
+	{112, 16, 112},
+	{8, 68, 8},
+	{64, 64, 32},
+	{16, 16, 28},
+	{16, 16, 72},
+	{16, 16, 80},
+	{76, 76, 36},
+	{15, 15, 44},
+	{68, 68, 8},
+	{16, 16, 8},
+	{16, 16, 12},
+	{112, 112, 0},
+	{12, 12, 0},
+	{0, 0, 4},
+	{72, 88, 72},
+	{80, 88, 80},
+	{96, 88, 96},
+	{64, 88, 32},
+	{68, 16, 52},
+	{111, 0, 56},
+	{111, 16, 60},
+	{76, 91, 36},
+	{64, 112, 40},
+	{16, 92, 112},
+	{68, 88, 8},
+	{16, 0, 8},
+	{16, 112, 12},
+	{112, 12, 0},
+	{12, 112, 16},
+	{84, 112, 16},
+	{12, 112, 0},
+	{100, 12, 112},
+	{0, 112, 32},
+	{16, 12, 112},
+	{112, 12, 24},
+	{16, 112, 116},
+}
+
+// compatColors the boot roms colours, mostly in groups of 4 though some palettes
+// start part way through a group
+var compatColors = [...]uint16{
+	0x7FFF, 0x32BF, 0x00D0, 0x0000,
+	0x639F, 0x4279, 0x15B0, 0x04CB,
+	0x7FFF, 0x6E31, 0x454A, 0x0000,
+	0x7FFF, 0x1BEF, 0x0200, 0x0000,
+	0x7FFF, 0x421F, 0x1CF2, 0x0000,
+	0x7FFF, 0x5294, 0x294A, 0x0000,
+	0x7FFF, 0x03FF, 0x012F, 0x0000,
+	0x7FFF, 0x03EF, 0x01D6, 0x0000,
+	0x7FFF, 0x42B5, 0x3DC8, 0x0000,
+	0x7E74, 0x03FF, 0x0180, 0x0000,
+	0x67FF, 0x77AC, 0x1A13, 0x2D6B,
+	0x7ED6, 0x4BFF, 0x2175, 0x0000,
+	0x53FF, 0x4A5F, 0x7E52, 0x0000,
+	0x4FFF, 0x7ED2, 0x3A4C, 0x1CE0,
+	0x03ED, 0x7FFF, 0x255F, 0x0000,
+	0x036A, 0x021F, 0x03FF, 0x7FFF,
+	0x7FFF, 0x01DF, 0x0112, 0x0000,
+	0x231F, 0x035F, 0x00F2, 0x0009,
+	0x7FFF, 0x03EA, 0x011F, 0x0000,
+	0x299F, 0x001A, 0x000C, 0x0000,
+	0x7FFF, 0x027F, 0x001F, 0x0000,
+	0x7FFF, 0x03E0, 0x0206, 0x0120,
+	0x7FFF, 0x7EEB, 0x001F, 0x7C00,
+	0x7FFF, 0x3FFF, 0x7E00, 0x001F,
+	0x7FFF, 0x03FF, 0x001F, 0x0000,
+	0x03FF, 0x001F, 0x000C, 0x0000,
+	0x7FFF, 0x033F, 0x0193, 0x0000,
+	0x0000, 0x4200, 0x037F, 0x7FFF,
+	0x7FFF, 0x7E8C, 0x7C00, 0x0000,
+	0x7FFF, 0x1BEF, 0x6180, 0x0000,
+}
+
+// compatCombo get the palette for an entry in compatCombos
+func compatCombo(index byte) CompatPalette {
+	combo := compatCombos[index]
+	var palette CompatPalette
+	copy(palette.OBJ0[:], compatColors[combo[0]:])
+	copy(palette.OBJ1[:], compatColors[combo[1]:])
+	copy(palette.BG[:], compatColors[combo[2]:])
+	return palette
+}
+
+// FindCompatPalette pick the palette the CGB boot rom would use for a DMG game
+// from its title checksum and 4th title character
+func FindCompatPalette(nintendo bool, checksum, letter byte) CompatPalette {
+	if !nintendo {
+		return DefaultCompatPalette
+	}
+
+	for i, sum := range compatChecksums {
+		if sum != checksum {
+			continue
+		}
+		if i >= compatFirstDuplicate && compatLetters[i-compatFirstDuplicate] != letter {
+			continue
+		}
+		return compatCombo(compatPaletteIndex[i] & 0x7F)
+	}
+
+	return DefaultCompatPalette
+}
+
+// CompatComboPalette get the palette for a button combo like "left+b"
+func CompatComboPalette(combo string) (CompatPalette, error) {
+	palette, found := CompatComboPalettes[strings.ToLower(combo)]
+	if !found {
+		return palette, fmt.Errorf("Unknown palette combo %q, should be a direction optionally followed by +a or +b", combo)
+	}
+	return palette, nil
+}
+
+// EnableCompatMode colourise a DMG game like a CGB does by loading palette into
+// the colour palettes and mapping the DMG palettes through them
+func (p *PPU) EnableCompatMode(palette CompatPalette) {
+	p.CompatMode = true
+	loadCompatColors(&p.LCD.BGPalettes, 0, palette.BG)
+	loadCompatColors(&p.LCD.OBJPalettes, 0, palette.OBJ0)
+	loadCompatColors(&p.LCD.OBJPalettes, 1, palette.OBJ1)
+}
+
+// loadCompatColors write colors into palette number index of the colour palettes
+func loadCompatColors(palettes *ColorPalettes, index int, colors [4]uint16) {
+	for i, c := range colors {
+		palettes.RAM[index*8+i*2] = byte(c)
+		palettes.RAM[index*8+i*2+1] = byte(c >> 8)
+	}
+}
@@ -0,0 +1,49 @@
+package ppu
+
+import "testing"
+
+// TestCompatTables check the boot rom tables line up with each other
+func TestCompatTables(t *testing.T) {
+	if len(compatPaletteIndex) != len(compatChecksums) {
+		t.Fatalf("%d palette indexes for %d checksums", len(compatPaletteIndex), len(compatChecksums))
+	}
+
+	for i, index := range compatPaletteIndex {
+		if int(index&0x7F) >= len(compatCombos) {
+			t.Errorf("Checksum %d uses combo %d, there are only %d", i, index&0x7F, len(compatCombos))
+		}
+	}
+
+	for i, combo := range compatCombos {
+		for _, start := range combo {
+			if int(start)+4 > len(compatColors) {
+				t.Errorf("Combo %d uses colours past the end of the table", i)
+			}
+		}
+	}
+}
+
+// TestFindCompatPalette check titles are found by checksum and 4th letter
+func TestFindCompatPalette(t *testing.T) {
+	yellowRed := samePalette([4]uint16{0x7FFF, 0x03FF, 0x001F, 0x0000})
+
+	tests := []struct {
+		name     string
+		nintendo bool
+		checksum byte
+		letter   byte
+		want     CompatPalette
+	}{
+		{"TETRIS", true, 0xDB, 'R', yellowRed},
+		{"not nintendo", false, 0xDB, 'R', DefaultCompatPalette},
+		{"unlisted", true, 0x02, 'A', DefaultCompatPalette},
+		{"POKEMON BLUE", true, 0x61, 'E', compatCombo(0x0B)},
+		{"wrong letter", true, 0x61, 'Z', DefaultCompatPalette},
+	}
+
+	for _, test := range tests {
+		if got := FindCompatPalette(test.nintendo, test.checksum, test.letter); got != test.want {
+			t.Errorf("%s: got %v, want %v", test.name, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package ppu
+
+import (
+	"image"
+	"image/color"
+)
+
+// Palette the colours used to draw the screen, with a separate set of
+// colours for the background/window and each of the object palettes
+type Palette struct {
+	Name   string
+	Colors [3][4]color.RGBA // indexed by PaletteBG, PaletteOBP0 and PaletteOBP1
+}
+
+// NewPalette create a palette that uses colors for the background and both object palettes
+func NewPalette(name string, colors [4]color.RGBA) Palette {
+	return Palette{Name: name, Colors: [3][4]color.RGBA{colors, colors, colors}}
+}
+
+// Color get the colour pixel is drawn with, CGB pixels carry their own colour
+func (p *Palette) Color(pixel Pixel) color.RGBA {
+	if pixel.CGB {
+		return RGB15ToRGBA(pixel.RGB15)
+	}
+	return p.Colors[pixel.Palette%3][pixel.Color&0x03]
+}
+
+// GreyPalette the fixed grey palette used when converting the screen to an
+// image, matches the one used by the dmg-acid2 reference images
+var GreyPalette = NewPalette("grey", [4]color.RGBA{
+	{R: 0xFF, G: 0xFF, B: 0xFF, A: 0xFF},
+	{R: 0xAA, G: 0xAA, B: 0xAA, A: 0xFF},
+	{R: 0x55, G: 0x55, B: 0x55, A: 0xFF},
+	{R: 0x00, G: 0x00, B: 0x00, A: 0xFF},
+})
+
+// Image convert the FinalScreen into a 160x144 image using the given palette
+func (s *Screen) Image(palette Palette) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, 160, 144))
+
+	for y := range 144 {
+		for x := range 160 {
+			img.SetRGBA(x, y, palette.Color(s.FinalScreen[y][x]))
+		}
+	}
+
+	return img
+}
@@ -3,7 +3,6 @@ package ppu
 import "log"
 
 // LCDReg the lcd IO registers
-// TODO - implement CGB palette stuff
 type LCDReg struct {
 	Control byte // LCD Control byte (0xFF40)
 	Stat    byte // LCD Status byte aka STAT (0xFF41)
@@ -22,6 +21,10 @@ type LCDReg struct {
 	WY  byte // Window Y pos (0xFF4A)
 	WX  byte // Window X pos (0xFF4B) - NOTE WX is position plus 7 so WX=7 is very left
 	PrevOAM byte // the previous oam data
+
+	// Palettes (cgb mode)
+	BGPalettes  ColorPalettes // BG colour palettes (0xFF68 - 0xFF69)
+	OBJPalettes ColorPalettes // obj colour palettes (0xFF6A - 0xFF6B)
 }
 
 // ReadByte read and return value at addr
@@ -63,6 +66,18 @@ func (l *LCDReg) ReadByte(addr uint16) byte {
 	case 0xFF4B: // WX
 		return l.WX
 
+	case 0xFF68: // BCPS
+		return l.BGPalettes.readSpec()
+
+	case 0xFF69: // BCPD
+		return l.BGPalettes.readData()
+
+	case 0xFF6A: // OCPS
+		return l.OBJPalettes.readSpec()
+
+	case 0xFF6B: // OCPD
+		return l.OBJPalettes.readData()
+
 	default:
 		// log.Println("Unkown read in LCD IO at unkown addr:", addr)
 	}
@@ -104,14 +119,26 @@ func (l *LCDReg) WriteByte(addr uint16, data byte) {
 
 	case 0xFF4B: // WX
 		l.WX = data
+
+	case 0xFF68: // BCPS
+		l.BGPalettes.writeSpec(data)
+
+	case 0xFF69: // BCPD
+		l.BGPalettes.writeData(data)
+
+	case 0xFF6A: // OCPS
+		l.OBJPalettes.writeSpec(data)
+
+	case 0xFF6B: // OCPD
+		l.OBJPalettes.writeData(data)
 		
 	default:
 		log.Println("Unkown write to LCD IO at unkown addr:", addr)
 	}
 }
 
-// isLcdOn return whether or not the LCD is turned on
-func (l *LCDReg) isLcdOn() bool {
+// LCDOn return whether or not the LCD is turned on
+func (l *LCDReg) LCDOn() bool {
 	return (l.Control >> 7) & 0x01 == 0x01
 }
 
@@ -122,7 +149,7 @@ func (l *LCDReg) objEnabled() bool {
 }
 
 // EnableBGWin return whether or not to draw the background and window
-// TODO - if convert to CGB make sure to convert this to priority
+// In CGB mode this is the BG/window master priority instead
 func (l *LCDReg) EnableBGWin() bool {
 	return l.Control & 0x01 == 0x01
 }
@@ -134,6 +161,11 @@ func (l *LCDReg) WindowEnabled() bool {
 		return false
 	}
 
+	return l.windowBit()
+}
+
+// windowBit return whether the window enable bit in Control is set, ignoring bit 0
+func (l *LCDReg) windowBit() bool {
 	return (l.Control >> 5) & 0x01 == 0x01
 }
 
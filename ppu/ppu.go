@@ -19,18 +19,19 @@ const (
 )
 
 type VideoRam struct {
-	RAM [0x2000]byte
+	RAM  [2][0x2000]byte // bank 1 is only used in CGB mode
+	Bank byte            // the bank the CPU reads and writes (VBK 0xFF4F)
 }
 
 // ReadByte read byte from active bank in vram
 func (v *VideoRam) ReadByte(addr uint16) byte {
 	// bankAddr := addr & 0x1FFF
-	return v.RAM[addr]
+	return v.RAM[v.Bank&0x01][addr]
 }
 
 // WriteByte write given data to addr
 func (v *VideoRam) WriteByte(addr uint16, data uint8) {
-	v.RAM[addr] = data
+	v.RAM[v.Bank&0x01][addr] = data
 
 }
 
@@ -60,11 +61,18 @@ type PPU struct {
 	VRAM             VideoRam
 	OAM              OAM // the Object Attribute Memory
 	LCD              LCDReg
-	WLY              byte            // the LY for the window
+	WLY              byte            // the internal line counter for the window, only increased on lines the window is drawn
+	windowTriggered  bool            // whether WY has matched LY at some point this frame
+	windowWrap       bool            // whether the window was drawn with WX=166 last line, so it covers all of this one
 	RequestInterrupt func(code byte) // function pointer to request interrupts
 	timer            *timer.Timer
 	Screen           Screen // The screen to store the scanlines in
+	FrameCount       int    // the number of frames completed (increased on entering VBlank)
 	cycles           uint16 // the current cycles for the current scanline
+	CGBMode          bool   // whether to use the CGB VRAM bank, attribute maps and colour palettes
+	CompatMode       bool   // whether a DMG game is colourised through the colour palettes like on a CGB
+	OnHBlank         func() // called at the start of each HBlank while drawing (used for the CGB HBlank DMA)
+	OnVBlank         func() // called once the frame is finished on entering VBlank (used by the SGB)
 }
 
 // NewPPU create and return a new ppu
@@ -72,6 +80,10 @@ func NewPPU(timer *timer.Timer, requestInterrupt func(code byte)) *PPU {
 	ppu := new(PPU)
 	ppu.timer = timer
 	ppu.RequestInterrupt = requestInterrupt
+	for i := range 64 { // the CGB boot rom leaves the palettes white
+		ppu.LCD.BGPalettes.RAM[i] = 0xFF
+		ppu.LCD.OBJPalettes.RAM[i] = 0xFF
+	}
 	return ppu
 }
 
@@ -184,11 +196,17 @@ func (p *PPU) Step(cycles uint16) {
 			p.setPPUMode(VBlankMode)
 
 			p.WLY = 0
+			p.windowTriggered = false
+			p.windowWrap = false
+			p.FrameCount++
 
 			if p.LCD.modeOneInt() {
 				p.RequestInterrupt(lcdInt) // request lcd interrupt
 			}
 			p.RequestInterrupt(vBlankInt)
+			if p.OnVBlank != nil {
+				p.OnVBlank()
+			}
 		}
 	}
 
@@ -197,6 +215,9 @@ func (p *PPU) Step(cycles uint16) {
 		case (p.cycles <= 80) && p.Mode() != OAMScanMode:
 			// p.Screen.Reset() // TODO - CHECK THIS
 			p.setPPUMode(OAMScanMode)
+			if p.LCD.LY == p.LCD.WY { // latched for the rest of the frame even if WY changes
+				p.windowTriggered = true
+			}
 
 		case (p.cycles >= 81 && p.cycles <= 252) && p.Mode() != DrawMode: // NOTE - idk why 252, that's what a guy did
 			p.setPPUMode(DrawMode)
@@ -204,13 +225,16 @@ func (p *PPU) Step(cycles uint16) {
 		case (p.cycles >= 253 && p.cycles <= 455) && p.Mode() != HBlankMode:
 			p.setPPUMode(HBlankMode)
 			p.renderScanline()
+			if p.OnHBlank != nil {
+				p.OnHBlank()
+			}
 		}
 	}
 }
 
 // renderScanline create the individual scanlines for the specific layers and combine them onto the main screen
 func (p *PPU) renderScanline() {
-	if !p.LCD.isLcdOn() {
+	if !p.LCD.LCDOn() {
 		return
 	}
 
@@ -250,8 +274,10 @@ func (p *PPU) scanOAM(scanY uint8) []Sprite {
 		}
 		
 		sprite := p.getSprite(i)
-		
-		if scanY+16 >= sprite.PosY && scanY+16 < sprite.PosY+spriteHeight {
+
+		// done in ints as scanY+16 overflows a byte on the lower lines
+		top := int(sprite.PosY) - 16
+		if int(scanY) >= top && int(scanY) < top+int(spriteHeight) {
 			sprites = append(sprites, sprite)
 		}
 	}
@@ -261,51 +287,70 @@ func (p *PPU) scanOAM(scanY uint8) []Sprite {
 
 // DrawBGScanline draw a background scanline and push it to the screen layer
 func (p *PPU) DrawBGScanline() {
-	if !p.LCD.EnableBGWin() {
+	if !p.CGBMode && !p.LCD.EnableBGWin() { // the BG is always drawn in CGB mode
 		return
 	}
 
 	tileMapAddr := p.LCD.BGTileMap()
 
-	for col := range uint8(159) {
+	for col := range uint8(160) {
 		x := col + p.LCD.SCX
 		y := p.LCD.LY + p.LCD.SCY
-		pixelVal := p.getTilePixel(x, y, tileMapAddr)
-		color := (p.LCD.BGP >> (2 * pixelVal)) & 0x03
-		p.Screen.Background[p.LCD.LY][col] = Pixel{Color: color, Opaque: true}
+		pixelVal, attr := p.getTilePixel(x, y, tileMapAddr)
+		p.Screen.Background[p.LCD.LY][col] = p.bgPixel(pixelVal, attr)
 	}
 }
 
 // DrawWinScanline draw a window scanline and push it to the screen layer
+// The window is shown once LY has matched WY this frame and only moves
+// its line counter (WLY) on the lines it actually gets drawn on.
+// With WX=166 the window starts right at the end of the line and keeps
+// going through the whole of the next line from its left edge
 func (p *PPU) DrawWinScanline() {
-	if !p.LCD.WindowEnabled() || p.LCD.LY < p.LCD.WY {
+	enabled := p.LCD.WindowEnabled()
+	if p.CGBMode { // LCDC bit 0 doesn't turn off the window in CGB mode
+		enabled = p.LCD.windowBit()
+	}
+
+	wrap := p.windowWrap
+	p.windowWrap = false
+	if !enabled || !p.windowTriggered || (p.LCD.WX > 166 && !wrap) {
 		return
 	}
 
 	tilemapAddr := p.LCD.WinTileMap()
-	var pixelDrawn bool
-
-	for x := range uint8(159) {
-		winX := 0 - (int(p.LCD.WX) - 7) + int(x)
+	startX := int(p.LCD.WX) - 7 // negative for WX 0-6, which cuts off the left of the window
+	if wrap {
+		startX = 0
+	} else if p.LCD.WX == 166 {
+		p.windowWrap = true
+	}
 
-		// don't draw if pixel off screen
-		if winX < 0 || winX >= 160 {
-			continue
-		}
+	for col := max(startX, 0); col < 160; col++ {
+		winX := col - startX
+		pixelVal, attr := p.getTilePixel(uint8(winX), p.WLY, tilemapAddr)
+		p.Screen.Window[p.LCD.LY][col] = p.bgPixel(pixelVal, attr)
+	}
 
-		pixel := p.getTilePixel(uint8(winX), p.WLY, tilemapAddr)
+	p.WLY++
+}
 
-		p.Screen.Window[p.LCD.LY][x] = Pixel{Color: pixel, Opaque: true}
-		pixelDrawn = true
+// bgPixel create the pixel for a BG or window colour index, using BGP or
+// the CGB colour palette from the tile attributes
+func (p *PPU) bgPixel(pixelVal, attr byte) Pixel {
+	if p.CGBMode {
+		rgb := p.LCD.BGPalettes.color(attr&0x07, pixelVal)
+		return Pixel{Color: pixelVal, Index: pixelVal, Opaque: true, CGB: true, RGB15: rgb, Priority: attr&0x80 != 0}
 	}
 
-	if pixelDrawn {
-		p.WLY += 1
+	color := (p.LCD.BGP >> (2 * pixelVal)) & 0x03
+	if p.CompatMode {
+		return Pixel{Color: color, Index: pixelVal, Opaque: true, CGB: true, RGB15: p.LCD.BGPalettes.color(0, color)}
 	}
+	return Pixel{Color: color, Index: pixelVal, Opaque: true}
 }
 
 // DrawObjectScanline draw a scanline for the objects and push to object layer in the screen
-// TODO - maybe split into seperate functions and simplify
 func (p *PPU) DrawObjectScanline() {
 	if !p.LCD.objEnabled() {
 		return
@@ -313,66 +358,114 @@ func (p *PPU) DrawObjectScanline() {
 
 	p.Screen.Objects[p.LCD.LY] = [160]Pixel{}
 	sprites := p.scanOAM(p.LCD.LY)
-	
+	height := int(p.LCD.objSize())
+
 	for i := range sprites {
 		sprite := sprites[i]
 
-		var spriteNum uint16
-		if p.LCD.objSize() == 16 {
-			spriteNum = uint16(sprite.Index) & 0xFE
-		} else {
-			spriteNum = uint16(sprite.Index) & 0xFF
+		spriteNum := uint16(sprite.Index)
+		if height == 16 { // 8x16 objects ignore bit 0 of the tile index
+			spriteNum &= 0xFE
 		}
-		
-		var spriteY uint16
-		height := uint16(p.LCD.objSize())
-		
+
+		spriteY := int(p.LCD.LY) - (int(sprite.PosY) - 16) // the row of the object being drawn
 		if sprite.yFlip() {
-			spriteY = (height-1 - (uint16(p.LCD.LY) - (uint16(sprite.PosY) - 16))) // TODO - very gross, check
-		} else {
-			spriteY = (uint16(p.LCD.LY) - (uint16(sprite.PosY) - 16))
+			spriteY = height - 1 - spriteY
 		}
 
-		spriteDataAddr := 0x8000 + (spriteNum * 16) + (spriteY * 2)
-		spriteDataLow := p.ReadByte(spriteDataAddr)
-		spriteDataHigh := p.ReadByte(spriteDataAddr+1)
+		var bank byte
+		if p.CGBMode && sprite.bank() {
+			bank = 1
+		}
 
-		for pixel := range uint8(8) {
-			posX := sprite.PosX - 8 // the relative posX
-			outOfRangeLeft := (int(sprite.PosX) - 8) + int(pixel) <= 0
-			outOfRangeRight := (sprite.PosX - 8) + pixel >= 160
-			if outOfRangeLeft || outOfRangeRight {
-				continue
-			}
+		spriteDataAddr := 0x8000 + (spriteNum * 16) + uint16(spriteY*2)
+		spriteDataLow := p.readVRAM(bank, spriteDataAddr)
+		spriteDataHigh := p.readVRAM(bank, spriteDataAddr+1)
 
-			coverPixel := p.Screen.Background[p.LCD.LY][posX+pixel].Color != 0 || p.Screen.Window[p.LCD.LY][posX+pixel].Color != 0
-			if p.LCD.EnableBGWin() && sprite.priority() && coverPixel {
+		for pixel := range 8 {
+			screenX := int(sprite.PosX) - 8 + pixel
+			if screenX < 0 || screenX >= 160 {
 				continue
 			}
 
-			var pixelNum byte = pixel
+			pixelNum := uint8(pixel)
 			if sprite.xFlip() {
-				pixelNum = 7-pixel
+				pixelNum = 7 - pixelNum
 			}
 
 			pixelVal := getPixel(spriteDataLow, spriteDataHigh, pixelNum)
-			color := (p.getDMGPalette(&sprite) >> (pixelVal * 2)) & 0x03
-			if pixelVal != 0 && p.Screen.Objects[p.LCD.LY][posX+pixel].Opaque {
-				if !p.solveSpriteCollision(&sprite, p.Screen.Objects[p.LCD.LY][posX+pixel].Sprite) {
-					continue
-				}
+			if pixelVal == 0 { // colour 0 is transparent for objects
+				continue
 			}
-			p.Screen.Objects[p.LCD.LY][posX+pixel] = Pixel{Color: color, Opaque: pixelVal != 0, Sprite: &sprite}
+
+			current := p.Screen.Objects[p.LCD.LY][screenX]
+			if current.Opaque && !p.solveSpriteCollision(&sprite, current.Sprite) {
+				continue
+			}
+
+			p.Screen.Objects[p.LCD.LY][screenX] = p.objPixel(&sprite, pixelVal)
+		}
+	}
+
+	p.applyBGPriority()
+}
+
+// objPixel create the pixel for an object colour index using OBP0/OBP1 or
+// the objects CGB colour palette
+func (p *PPU) objPixel(sprite *Sprite, pixelVal byte) Pixel {
+	if p.CGBMode {
+		rgb := p.LCD.OBJPalettes.color(sprite.cgbPalette(), pixelVal)
+		return Pixel{Color: pixelVal, Index: pixelVal, Opaque: true, Sprite: sprite, CGB: true, RGB15: rgb}
+	}
+
+	color := (p.getDMGPalette(sprite) >> (pixelVal * 2)) & 0x03
+	palette := PaletteOBP0
+	if sprite.dmgPalette() {
+		palette = PaletteOBP1
+	}
+	if p.CompatMode {
+		rgb := p.LCD.OBJPalettes.color(palette-PaletteOBP0, color)
+		return Pixel{Color: color, Index: pixelVal, Palette: palette, Opaque: true, Sprite: sprite, CGB: true, RGB15: rgb}
+	}
+	return Pixel{Color: color, Index: pixelVal, Palette: palette, Opaque: true, Sprite: sprite}
+}
+
+// applyBGPriority hide the object pixels whose sprite (or in CGB mode the BG tile
+// attributes) has the BG priority flag set and sits on top of a non-zero BG/window
+// colour index. Done after the objects are resolved as a hidden object still hides
+// any lower priority objects beneath it. In CGB mode clearing LCDC bit 0 puts the
+// objects above everything instead
+func (p *PPU) applyBGPriority() {
+	if !p.LCD.EnableBGWin() {
+		return
+	}
+
+	line := p.LCD.LY
+	for x := range 160 {
+		obj := p.Screen.Objects[line][x]
+		if !obj.Opaque {
+			continue
+		}
+
+		bg := p.Screen.Background[line][x]
+		if p.Screen.Window[line][x].Opaque {
+			bg = p.Screen.Window[line][x]
+		}
+
+		if bg.Index != 0 && (obj.Sprite.priority() || bg.Priority) {
+			p.Screen.Objects[line][x] = Pixel{}
 		}
 	}
 }
 
 // solveSpriteCollision take a sprite and the sprite currently at the location and return true if the newSprite should take priority
+// On DMG the object with the lower X wins, with ties going to the one earlier in OAM.
+// On CGB only the OAM position matters
 func (p *PPU) solveSpriteCollision(newSprite, oldSprite *Sprite) bool {
-	if newSprite.Position <= oldSprite.Position || newSprite.PosX < oldSprite.PosX {
-		return true
+	if !p.CGBMode && newSprite.PosX != oldSprite.PosX {
+		return newSprite.PosX < oldSprite.PosX
 	}
-	return false
+	return newSprite.Position < oldSprite.Position
 }
 
 // getDMGPalette get the actual palette data for a given obj
@@ -385,24 +478,56 @@ func (p *PPU) getDMGPalette(sprite *Sprite) byte {
 }
 
 // getTilePixel get the pixel value for the pixel at x and y based on tileMapAddr
+// along with the tiles CGB attributes from VRAM bank 1 (always 0 in DMG mode)
 // Heavily based on https://github.com/ablakey/gameboy/blob/dc2abcae57f271cb7873f9fd5cc77cd6e30fb4d1/src/guest/systems/ppu.rs#L5
 // As I'm too stupid to understand myself
-func (p *PPU) getTilePixel(x, y uint8, tileMapAddr uint16) uint8 {
+func (p *PPU) getTilePixel(x, y uint8, tileMapAddr uint16) (pixel uint8, attr byte) {
 	tileRowNum := y / 8
 	tileColNum := x / 8
 	tileNum := uint16(tileRowNum)*32 + uint16(tileColNum)
 
-	tileDataNum := p.ReadByte(tileMapAddr + tileNum)
+	tileDataNum := p.readVRAM(0, tileMapAddr+tileNum)
 	tileDataAddr := getTileDataAddress(p.LCD.TileDataAddrMode(), tileDataNum)
+	if p.CGBMode {
+		attr = p.readVRAM(1, tileMapAddr+tileNum)
+	}
 	
 	pixelRowNum := y % 8
 	pixelColNum := x % 8
+	if attr&0x40 != 0 { // y flip
+		pixelRowNum = 7 - pixelRowNum
+	}
+	if attr&0x20 != 0 { // x flip
+		pixelColNum = 7 - pixelColNum
+	}
+	bank := (attr >> 3) & 0x01
 
 	tileRowIndex := tileDataAddr + (uint16(pixelRowNum) * 2)
-	tileDataLow := p.ReadByte(tileRowIndex)
-	tileDataHigh := p.ReadByte(tileRowIndex + 1)
+	tileDataLow := p.readVRAM(bank, tileRowIndex)
+	tileDataHigh := p.readVRAM(bank, tileRowIndex+1)
+
+	return getPixel(tileDataLow, tileDataHigh, pixelColNum), attr
+}
 
-	return getPixel(tileDataLow, tileDataHigh, pixelColNum)
+// readVRAM read addr (0x8000 - 0x9FFF) from the given VRAM bank regardless of
+// the bank the CPU has selected
+func (p *PPU) readVRAM(bank byte, addr uint16) byte {
+	return p.VRAM.RAM[bank&0x01][addr-0x8000]
+}
+
+// TransferTiles get the tile data for the first 256 tiles of the background
+// map, 20 tiles per row like they're laid out on the screen. This is how the
+// game sends data to the SGB, which reads it off the screen
+func (p *PPU) TransferTiles() [4096]byte {
+	var data [4096]byte
+	for i := range 256 {
+		mapAddr := p.LCD.BGTileMap() + uint16(i/20)*32 + uint16(i%20)
+		tileAddr := getTileDataAddress(p.LCD.TileDataAddrMode(), p.readVRAM(0, mapAddr))
+		for b := range 16 {
+			data[i*16+b] = p.readVRAM(0, tileAddr+uint16(b))
+		}
+	}
+	return data
 }
 
 // getPixel get the two bits for the color pixel based on the two bytes and the pixel num
@@ -6,6 +6,7 @@ type Screen struct {
 	Background  [144][160]Pixel // the pixels for the Background layer
 	Window      [144][160]Pixel // The pixels for the Window layer
 	Objects     [144][160]Pixel // the pixels for the Objects layer
+	Border      *[224][256]Pixel // the SGB border drawn around the screen, nil if there isn't one
 }
 
 // Reset reset the screen (usually done at the beginning of each frame)
@@ -14,6 +15,7 @@ func (s *Screen) Reset() {
 	s.Background	= [144][160]Pixel{}
 	s.Window		= [144][160]Pixel{}
 	s.Objects		= [144][160]Pixel{}
+	// the border is kept as it only changes when the SGB gets a new one
 }
 
 // CombineLine combine the 3 layers from line line together and add them to the line in FinalScreen
@@ -38,10 +40,28 @@ func (s *Screen) CombineLine(line int)  {
 	s.FinalScreen[line] = screen
 }
 
+// where the screen goes inside the SGB border
+const (
+	BorderScreenX = 48
+	BorderScreenY = 40
+)
+
+// The palettes a pixel can be drawn with, used by the frontend to colour
+// the background and each of the object palettes separately
+const (
+	PaletteBG byte = iota
+	PaletteOBP0
+	PaletteOBP1
+)
+
 // Pixel - a struct to hold pixel data
 type Pixel struct {
 	Color   byte // color number for the pixel
-	Palette byte // Value for which palette to use
+	Index   byte // the raw colour index before the palette is applied (used for BG-over-OBJ priority)
+	Palette byte // Value for which palette to use (PaletteBG, PaletteOBP0 or PaletteOBP1)
 	Opaque bool // whether or not to draw that pixel (true = drawn)
 	Sprite *Sprite // the sprite this pixel is sourced from (only used for objects to determine priority)
+	CGB      bool   // whether the pixel is drawn with RGB15 instead of a DMG palette
+	RGB15    uint16 // the 15-bit colour from the CGB colour palettes
+	Priority bool   // the BG-to-OBJ priority bit from the CGB BG attributes
 }
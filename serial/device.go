@@ -0,0 +1,144 @@
+package serial
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+	"unicode"
+)
+
+// Device something plugged into the serial port
+type Device interface {
+	// Exchange receive the byte the game clocked out and return the byte shifted in
+	Exchange(data byte) byte
+}
+
+// ExternalClock a device that can clock transfers itself, for games waiting on the external clock
+type ExternalClock interface {
+	// Poll is called every tick, listening is whether the game is waiting on the
+	// external clock with out in SB. Returns the byte to shift in and true if a transfer happened
+	Poll(listening bool, out byte) (in byte, ok bool)
+}
+
+// Logger a device that writes every byte sent and received to a log with a
+// timestamp, passing them on to the device behind it (nil = nothing plugged in)
+type Logger struct {
+	w      io.Writer
+	file   *os.File // the file being logged to, nil if it isn't owned by the logger
+	device Device
+}
+
+// NewLogger create a logger writing to w in front of device
+func NewLogger(w io.Writer, device Device) *Logger {
+	return &Logger{w: w, device: device}
+}
+
+// OpenLogger create a logger writing to the file at path, or stdout if path is "-"
+func OpenLogger(path string, device Device) (*Logger, error) {
+	if path == "-" {
+		return NewLogger(os.Stdout, device), nil
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to create serial log: %v", err)
+	}
+
+	logger := NewLogger(file, device)
+	logger.file = file
+	return logger, nil
+}
+
+// Exchange log data and the answer from the device behind the logger
+func (l *Logger) Exchange(data byte) byte {
+	in := byte(0xFF)
+	if l.device != nil {
+		in = l.device.Exchange(data)
+	}
+
+	l.log('>', data)
+	l.log('<', in)
+	return in
+}
+
+// Poll pass on transfers started by the device behind the logger
+func (l *Logger) Poll(listening bool, out byte) (byte, bool) {
+	clock, ok := l.device.(ExternalClock)
+	if !ok {
+		return 0, false
+	}
+
+	in, ok := clock.Poll(listening, out)
+	if ok {
+		l.log('>', out)
+		l.log('<', in)
+	}
+	return in, ok
+}
+
+// log write a byte with its direction (> sent by the game, < received) and the
+// character if it's printable
+func (l *Logger) log(direction rune, data byte) {
+	line := fmt.Sprintf("%s %c %02X", time.Now().Format("2006-01-02 15:04:05.000000"), direction, data)
+	if unicode.IsPrint(rune(data)) && data < 0x80 {
+		line += fmt.Sprintf(" %q", rune(data))
+	}
+	fmt.Fprintln(l.w, line)
+}
+
+// Close close the log file and the device behind the logger
+func (l *Logger) Close() error {
+	if closer, ok := l.device.(io.Closer); ok {
+		closer.Close()
+	}
+	if l.file != nil {
+		return l.file.Close()
+	}
+	return nil
+}
+
+// Feeder a device that plays the other side using bytes from a file, given one
+// at a time whenever the game clocks a transfer or waits on the external clock
+// Once they run out it acts like nothing is plugged in
+type Feeder struct {
+	data []byte
+	pos  int
+}
+
+// NewFeeder create a feeder that sends data
+func NewFeeder(data []byte) *Feeder {
+	return &Feeder{data: data}
+}
+
+// OpenFeeder create a feeder that sends the contents of the file at path
+func OpenFeeder(path string) (*Feeder, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read serial input: %v", err)
+	}
+	return NewFeeder(data), nil
+}
+
+// next get the next byte to send, 0xFF once they've run out
+func (f *Feeder) next() (byte, bool) {
+	if f.pos >= len(f.data) {
+		return 0xFF, false
+	}
+	f.pos++
+	return f.data[f.pos-1], true
+}
+
+// Exchange give the game the next byte
+func (f *Feeder) Exchange(data byte) byte {
+	in, _ := f.next()
+	return in
+}
+
+// Poll give the game the next byte if it's waiting for one
+func (f *Feeder) Poll(listening bool, out byte) (byte, bool) {
+	if !listening {
+		return 0, false
+	}
+	return f.next()
+}
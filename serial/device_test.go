@@ -0,0 +1,102 @@
+package serial
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// recordingDevice a device that answers with answer and remembers what it was sent
+type recordingDevice struct {
+	answer byte
+	sent   []byte
+	closed bool
+}
+
+func (d *recordingDevice) Exchange(data byte) byte {
+	d.sent = append(d.sent, data)
+	return d.answer
+}
+
+func (d *recordingDevice) Close() error {
+	d.closed = true
+	return nil
+}
+
+func TestLogger(t *testing.T) {
+	var out bytes.Buffer
+	device := &recordingDevice{answer: 0x42}
+	logger := NewLogger(&out, device)
+
+	if got := logger.Exchange('A'); got != 0x42 {
+		t.Errorf("Got 0x%02X, want the answer from the device behind the logger", got)
+	}
+	if len(device.sent) != 1 || device.sent[0] != 'A' {
+		t.Errorf("Device behind the logger got %v, want [0x41]", device.sent)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 || !strings.HasSuffix(lines[0], `> 41 'A'`) || !strings.HasSuffix(lines[1], "< 42 'B'") {
+		t.Errorf("Got log %q", lines)
+	}
+
+	if err := logger.Close(); err != nil || !device.closed {
+		t.Errorf("Close returned %v and closed the device %v", err, device.closed)
+	}
+}
+
+func TestLoggerNothingPluggedIn(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "serial.log")
+	logger, err := OpenLogger(path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := logger.Exchange(0x01); got != 0xFF {
+		t.Errorf("Got 0x%02X with nothing plugged in, want 0xFF", got)
+	}
+	if in, ok := logger.Poll(true, 0x01); ok {
+		t.Errorf("Poll transferred 0x%02X with nothing plugged in", in)
+	}
+	if err := logger.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Count(string(data), "\n"); got != 2 {
+		t.Errorf("Got %d lines in the log file, want 2", got)
+	}
+}
+
+func TestFeeder(t *testing.T) {
+	feeder := NewFeeder([]byte{0x01, 0x02, 0x03})
+
+	if got := feeder.Exchange(0xAA); got != 0x01 {
+		t.Errorf("Exchange got 0x%02X, want 0x01", got)
+	}
+	if _, ok := feeder.Poll(false, 0xAA); ok {
+		t.Error("Poll sent a byte when the game wasn't listening")
+	}
+	if got, ok := feeder.Poll(true, 0xAA); !ok || got != 0x02 {
+		t.Errorf("Poll got 0x%02X %v, want 0x02 true", got, ok)
+	}
+	feeder.Exchange(0xAA)
+
+	if got := feeder.Exchange(0xAA); got != 0xFF {
+		t.Errorf("Got 0x%02X once the bytes ran out, want 0xFF", got)
+	}
+	if _, ok := feeder.Poll(true, 0xAA); ok {
+		t.Error("Poll sent a byte once the bytes ran out")
+	}
+}
+
+func TestOpenFeederMissingFile(t *testing.T) {
+	if _, err := OpenFeeder(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("Opened a feeder for a file that doesn't exist")
+	}
+}
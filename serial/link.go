@@ -0,0 +1,182 @@
+package serial
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"time"
+)
+
+var LinkTimeout = time.Second // how long a transfer waits for the other side before giving up with 0xFF
+
+// The kinds of message sent over the link, each message is the kind followed by a data byte
+const (
+	msgTransfer = 0 // the side with the internal clock started a transfer
+	msgReply    = 1 // the other sides byte in return
+)
+
+// Link a link cable to another emulator over a TCP connection
+// The side clocking the transfer sends its byte and waits for the other side
+// to answer with its own
+type Link struct {
+	conn     net.Conn
+	requests chan byte // transfers started by the other side
+	replies  chan byte // answers to our transfers
+}
+
+// Listen wait for another emulator to connect on addr (e.g ":5000")
+func Listen(addr string) (*Link, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to listen for link: %v", err)
+	}
+	defer listener.Close()
+
+	log.Println("Waiting for link connection on", listener.Addr())
+	conn, err := listener.Accept()
+	if err != nil {
+		return nil, fmt.Errorf("Failed to accept link connection: %v", err)
+	}
+
+	log.Println("Link connected to", conn.RemoteAddr())
+	return newLink(conn), nil
+}
+
+// Dial connect to another emulator listening on addr (e.g "localhost:5000")
+func Dial(addr string) (*Link, error) {
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to connect link: %v", err)
+	}
+
+	log.Println("Link connected to", conn.RemoteAddr())
+	return newLink(conn), nil
+}
+
+// newLink create a link over conn and start reading from it
+func newLink(conn net.Conn) *Link {
+	l := &Link{conn: conn, requests: make(chan byte, 16), replies: make(chan byte, 16)}
+	go l.read()
+	return l
+}
+
+// read pass the messages from the other side to the request and reply channels
+// until the connection closes
+func (l *Link) read() {
+	defer close(l.requests)
+	defer close(l.replies)
+
+	msg := make([]byte, 2)
+	for {
+		if _, err := io.ReadFull(l.conn, msg); err != nil {
+			if err != io.EOF {
+				log.Println("Link disconnected:", err)
+			} else {
+				log.Println("Link disconnected")
+			}
+			return
+		}
+
+		switch msg[0] {
+		case msgTransfer:
+			l.requests <- msg[1]
+		case msgReply:
+			l.replies <- msg[1]
+		}
+	}
+}
+
+// send send a message to the other side, giving up after LinkTimeout
+func (l *Link) send(kind, data byte) error {
+	if err := l.conn.SetWriteDeadline(time.Now().Add(LinkTimeout)); err != nil {
+		return err
+	}
+	_, err := l.conn.Write([]byte{kind, data})
+	return err
+}
+
+// Exchange send data to the other side and wait for its byte in return
+// Returns 0xFF if the link is disconnected or the other side doesn't answer
+// within LinkTimeout
+func (l *Link) Exchange(data byte) byte {
+	l.dropLateReplies()
+	if err := l.send(msgTransfer, data); err != nil {
+		return 0xFF
+	}
+
+	timeout := time.NewTimer(LinkTimeout)
+	defer timeout.Stop()
+
+	requests := l.requests
+	for {
+		select {
+		case <-timeout.C:
+			log.Println("Link transfer timed out")
+			return 0xFF
+
+		case reply, ok := <-l.replies:
+			if !ok {
+				return 0xFF
+			}
+			return reply
+
+		case _, ok := <-requests: // both sides are clocking so neither is listening
+			if !ok {
+				requests = nil
+				continue
+			}
+			l.reply(0xFF)
+		}
+	}
+}
+
+// dropLateReplies throw away replies to transfers that already timed out so
+// they aren't taken as the answer to the next one
+func (l *Link) dropLateReplies() {
+	for {
+		select {
+		case _, ok := <-l.replies:
+			if !ok {
+				return
+			}
+		default:
+			return
+		}
+	}
+}
+
+// Poll answer a transfer started by the other side, SB is swapped with the
+// other sides byte if the game is listening, otherwise the other side gets 0xFF
+func (l *Link) Poll(listening bool, out byte) (byte, bool) {
+	var data byte
+	select {
+	case received, ok := <-l.requests:
+		if !ok {
+			return 0, false
+		}
+		data = received
+	default:
+		return 0, false
+	}
+
+	if !listening {
+		l.reply(0xFF)
+		return 0, false
+	}
+
+	l.reply(out)
+	return data, true
+}
+
+// reply answer a transfer started by the other side with data
+func (l *Link) reply(data byte) {
+	if err := l.send(msgReply, data); err != nil {
+		log.Println("Failed to answer link transfer:", err)
+	}
+}
+
+// Close disconnect the link
+func (l *Link) Close() error {
+	return l.conn.Close()
+}
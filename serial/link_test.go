@@ -0,0 +1,82 @@
+package serial
+
+import (
+	"net"
+	"testing"
+	"time"
+)
+
+// newTestLinks connect two links together with a pipe
+func newTestLinks(t *testing.T) (*Link, *Link) {
+	a, b := net.Pipe()
+	left, right := newLink(a), newLink(b)
+	t.Cleanup(func() {
+		left.Close()
+		right.Close()
+	})
+	return left, right
+}
+
+// pollUntil poll link as the listening side answering with out until the other
+// side starts a transfer, returns the byte it sent
+func pollUntil(t *testing.T, link *Link, out byte) byte {
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		if data, ok := link.Poll(true, out); ok {
+			return data
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Error("Other side never started a transfer")
+	return 0
+}
+
+func TestLinkExchange(t *testing.T) {
+	left, right := newTestLinks(t)
+
+	received := make(chan byte)
+	go func() { received <- pollUntil(t, right, 0x66) }()
+
+	if got := left.Exchange(0x55); got != 0x66 {
+		t.Errorf("Clocking side got 0x%02X, want 0x66", got)
+	}
+	if got := <-received; got != 0x55 {
+		t.Errorf("Listening side got 0x%02X, want 0x55", got)
+	}
+}
+
+func TestLinkTimeout(t *testing.T) {
+	defer func(timeout time.Duration) { LinkTimeout = timeout }(LinkTimeout)
+	LinkTimeout = 50 * time.Millisecond
+
+	left, right := newTestLinks(t)
+
+	start := time.Now()
+	if got := left.Exchange(0x55); got != 0xFF {
+		t.Errorf("Got 0x%02X with no answer, want 0xFF", got)
+	}
+	if elapsed := time.Since(start); elapsed < LinkTimeout {
+		t.Errorf("Gave up after %v, before the timeout", elapsed)
+	}
+
+	// answer the transfer that timed out, that reply mustn't be taken as the next answer
+	pollUntil(t, right, 0x66)
+	deadline := time.Now().Add(time.Second)
+	for len(left.replies) == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+
+	go pollUntil(t, right, 0x77)
+	if got := left.Exchange(0x56); got != 0x77 {
+		t.Errorf("Got 0x%02X, want 0x77 and not the late reply", got)
+	}
+}
+
+func TestLinkDisconnected(t *testing.T) {
+	left, right := newTestLinks(t)
+	right.Close()
+
+	if got := left.Exchange(0x55); got != 0xFF {
+		t.Errorf("Got 0x%02X from a closed link, want 0xFF", got)
+	}
+}
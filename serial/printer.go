@@ -0,0 +1,217 @@
+package serial
+
+import (
+	"image"
+	"image/color"
+)
+
+// The printer commands
+const (
+	printerInit   = 0x01 // clear the image buffer
+	printerPrint  = 0x02 // print the buffer
+	printerData   = 0x04 // add tile data to the buffer
+	printerStatus = 0x0F // just ask for the status
+)
+
+// The printer status bits
+const (
+	statusChecksum    = 0x01 // the last packets checksum didn't match
+	statusBusy        = 0x02 // printing
+	statusFull        = 0x04 // the image buffer is ready to print
+	statusUnprocessed = 0x08 // there's data in the buffer that hasn't been printed
+	statusPacketError = 0x10 // the last packet had an unknown command or was too long
+)
+
+const (
+	printerWidth   = 160      // the width of a print in pixels
+	bandSize       = 40 * 16  // each band of tile data is 2 rows of 20 tiles, 16 pixels tall
+	printerRAMSize = 0x2000   // the most tile data the printer can hold
+	busyPolls      = 2        // how many packets the printer stays busy for after printing
+	maxPacketData  = bandSize // the longest data a packet can carry
+)
+
+// Printer the Game Boy Printer, plugged into the serial port it receives packets from the game:
+// 0x88 0x33, command, compression, length (2 bytes), data, checksum (2 bytes) then
+// answers with 0x81 and its status on the two bytes after
+// Prints are joined together until one has a bottom margin, then passed to OnPrint
+type Printer struct {
+	OnPrint func(img *image.Gray) // called with each finished print
+
+	packet   []byte // the packet being received
+	status   byte
+	busyLeft int    // packets left until printing finishes
+	buffer   []byte // the tile data to print
+	page     []byte // the rows printed so far that haven't been passed to OnPrint, 1 byte per pixel
+}
+
+// NewPrinter create a printer that calls onPrint with each finished print
+func NewPrinter(onPrint func(img *image.Gray)) *Printer {
+	return &Printer{OnPrint: onPrint}
+}
+
+// Exchange receive a byte from the game and return the printers answer
+func (p *Printer) Exchange(data byte) byte {
+	pos := len(p.packet)
+	p.packet = append(p.packet, data)
+
+	switch {
+	case pos == 0 && data != 0x88, pos == 1 && data != 0x33: // not the magic bytes so wait for them
+		p.packet = p.packet[:0]
+		return 0x00
+	case pos < 6:
+		return 0x00
+	}
+
+	dataLen := int(p.packet[4]) | int(p.packet[5])<<8
+	if dataLen > maxPacketData {
+		p.status |= statusPacketError
+		p.packet = p.packet[:0]
+		return 0x00
+	}
+
+	checksumEnd := 6 + dataLen + 2
+	switch pos {
+	case checksumEnd - 1:
+		p.runPacket(dataLen)
+	case checksumEnd:
+		return 0x81 // the printer is connected
+	case checksumEnd + 1:
+		status := p.status
+		p.packet = p.packet[:0]
+		if p.busyLeft > 0 {
+			if p.busyLeft--; p.busyLeft == 0 {
+				p.status &^= statusBusy | statusFull
+			}
+		}
+		return status
+	}
+
+	return 0x00
+}
+
+// runPacket check the finished packets checksum and run its command
+func (p *Printer) runPacket(dataLen int) {
+	data := p.packet[6 : 6+dataLen]
+
+	var sum uint16
+	for _, b := range p.packet[2 : 6+dataLen] {
+		sum += uint16(b)
+	}
+	if sum != uint16(p.packet[6+dataLen])|uint16(p.packet[7+dataLen])<<8 {
+		p.status |= statusChecksum
+		return
+	}
+	p.status &^= statusChecksum | statusPacketError
+
+	switch p.packet[2] {
+	case printerInit:
+		p.buffer = p.buffer[:0]
+		p.status = 0
+		p.busyLeft = 0
+
+	case printerData:
+		if p.packet[3]&0x01 != 0 {
+			data = decompress(data)
+		}
+		if len(p.buffer)+len(data) <= printerRAMSize {
+			p.buffer = append(p.buffer, data...)
+		}
+		if len(p.buffer) > 0 {
+			p.status |= statusUnprocessed
+		}
+
+	case printerPrint:
+		if len(data) >= 4 {
+			p.print(data[1], data[2])
+		}
+
+	case printerStatus:
+
+	default:
+		p.status |= statusPacketError
+	}
+}
+
+// decompress undo the printers run length encoding, a control byte with bit 7
+// set repeats the next byte (control & 0x7F) + 2 times, otherwise the next
+// control + 1 bytes are copied
+func decompress(data []byte) []byte {
+	var out []byte
+	for i := 0; i < len(data); {
+		control := data[i]
+		i++
+
+		if control&0x80 != 0 {
+			if i >= len(data) {
+				break
+			}
+			for range int(control&0x7F) + 2 {
+				out = append(out, data[i])
+			}
+			i++
+			continue
+		}
+
+		end := min(i+int(control)+1, len(data))
+		out = append(out, data[i:end]...)
+		i = end
+	}
+	return out
+}
+
+// print draw the buffer onto the page using palette (like BGP, 0 = the default 0xE4)
+// The page is finished once a print has a bottom margin (the low nibble of margins)
+func (p *Printer) print(margins, palette byte) {
+	if palette == 0 {
+		palette = 0xE4
+	}
+
+	for band := range len(p.buffer) / bandSize {
+		rows := make([]byte, printerWidth*16)
+		for tile := range 40 {
+			tileData := p.buffer[band*bandSize+tile*16:]
+			tileX, tileY := (tile%20)*8, (tile/20)*8
+
+			for row := range 8 {
+				low, high := tileData[row*2], tileData[row*2+1]
+				for col := range 8 {
+					colorNum := (low>>(7-col))&0x01 | ((high>>(7-col))&0x01)<<1
+					shade := (palette >> (colorNum * 2)) & 0x03
+					rows[(tileY+row)*printerWidth+tileX+col] = 0xFF - shade*0x55
+				}
+			}
+		}
+		p.page = append(p.page, rows...)
+	}
+
+	p.buffer = p.buffer[:0]
+	p.status = (p.status | statusBusy | statusFull) &^ statusUnprocessed
+	p.busyLeft = busyPolls
+
+	if margins&0x0F != 0 {
+		p.Flush()
+	}
+}
+
+// Flush pass whatever has been printed to OnPrint
+func (p *Printer) Flush() {
+	if len(p.page) == 0 {
+		return
+	}
+
+	img := image.NewGray(image.Rect(0, 0, printerWidth, len(p.page)/printerWidth))
+	for i, shade := range p.page {
+		img.SetGray(i%printerWidth, i/printerWidth, color.Gray{Y: shade})
+	}
+	p.page = nil
+
+	if p.OnPrint != nil {
+		p.OnPrint(img)
+	}
+}
+
+// Close save anything left on the printer
+func (p *Printer) Close() error {
+	p.Flush()
+	return nil
+}
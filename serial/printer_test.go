@@ -0,0 +1,139 @@
+package serial
+
+import (
+	"bytes"
+	"image"
+	"testing"
+)
+
+// sendPacket send the printer a packet and return what it answered with on the
+// two bytes after the checksum. badChecksum sends a checksum that's off by one
+func sendPacket(p *Printer, command, compression byte, data []byte, badChecksum bool) (ack, status byte) {
+	packet := []byte{0x88, 0x33, command, compression, byte(len(data)), byte(len(data) >> 8)}
+	packet = append(packet, data...)
+
+	var sum uint16
+	for _, b := range packet[2:] {
+		sum += uint16(b)
+	}
+	if badChecksum {
+		sum++
+	}
+	packet = append(packet, byte(sum), byte(sum>>8), 0x00, 0x00)
+
+	var answers []byte
+	for _, b := range packet {
+		answers = append(answers, p.Exchange(b))
+	}
+	return answers[len(answers)-2], answers[len(answers)-1]
+}
+
+// repeatRun compress n copies of b with the printers run length encoding
+func repeatRun(b byte, n int) []byte {
+	var out []byte
+	for ; n > 0; n -= 129 {
+		out = append(out, 0x80|byte(min(n, 129)-2), b)
+	}
+	return out
+}
+
+// literalRun compress data as bytes copied as they are
+func literalRun(data []byte) []byte {
+	return append([]byte{byte(len(data) - 1)}, data...)
+}
+
+func TestPrinterStatus(t *testing.T) {
+	band := bytes.Repeat([]byte{0xFF}, bandSize)
+	printArgs := []byte{0x01, 0x00, 0xE4, 0x40} // no margins
+
+	tests := []struct {
+		name    string
+		command byte
+		data    []byte
+		bad     bool
+		want    byte
+	}{
+		{"init", printerInit, nil, false, 0x00},
+		{"data", printerData, band, false, statusUnprocessed},
+		{"end of data", printerData, nil, false, statusUnprocessed},
+		{"bad checksum", printerStatus, nil, true, statusUnprocessed | statusChecksum},
+		{"status clears checksum", printerStatus, nil, false, statusUnprocessed},
+		{"print", printerPrint, printArgs, false, statusBusy | statusFull},
+		{"still busy", printerStatus, nil, false, statusBusy | statusFull},
+		{"done printing", printerStatus, nil, false, 0x00},
+		{"unknown command", 0x07, nil, false, statusPacketError},
+		{"init clears errors", printerInit, nil, false, 0x00},
+	}
+
+	p := NewPrinter(nil)
+	for _, test := range tests {
+		ack, status := sendPacket(p, test.command, 0, test.data, test.bad)
+		if ack != 0x81 {
+			t.Errorf("%s: got ack 0x%02X, want 0x81", test.name, ack)
+		}
+		if status != test.want {
+			t.Errorf("%s: got status 0x%02X, want 0x%02X", test.name, status, test.want)
+		}
+	}
+}
+
+func TestPrinterCompressedData(t *testing.T) {
+	var raw, compressed []byte
+	literal := make([]byte, 40)
+	for i := range literal {
+		literal[i] = byte(i)
+	}
+	raw = append(raw, bytes.Repeat([]byte{0xAA}, 300)...)
+	raw = append(raw, literal...)
+	raw = append(raw, bytes.Repeat([]byte{0x00}, 300)...)
+	compressed = append(compressed, repeatRun(0xAA, 300)...)
+	compressed = append(compressed, literalRun(literal)...)
+	compressed = append(compressed, repeatRun(0x00, 300)...)
+
+	if got := decompress(compressed); !bytes.Equal(got, raw) {
+		t.Fatalf("decompress gave %d bytes that don't match the %d raw bytes", len(got), len(raw))
+	}
+
+	plain, packed := NewPrinter(nil), NewPrinter(nil)
+	sendPacket(plain, printerData, 0, raw, false)
+	sendPacket(packed, printerData, 1, compressed, false)
+	if !bytes.Equal(plain.buffer, packed.buffer) {
+		t.Error("Compressed and uncompressed data packets gave different buffers")
+	}
+}
+
+func TestPrinterOnPrint(t *testing.T) {
+	var prints []*image.Gray
+	p := NewPrinter(func(img *image.Gray) { prints = append(prints, img) })
+
+	band := make([]byte, bandSize)
+	for i := 0; i < bandSize; i += 2 { // every pixel colour 1, light grey with 0xE4
+		band[i] = 0xFF
+	}
+
+	sendPacket(p, printerInit, 0, nil, false)
+	sendPacket(p, printerData, 0, band, false)
+	sendPacket(p, printerPrint, 0, []byte{0x01, 0x00, 0xE4, 0x40}, false)
+	if len(prints) != 0 {
+		t.Fatal("Print without a bottom margin finished the page")
+	}
+
+	sendPacket(p, printerData, 0, band, false)
+	sendPacket(p, printerPrint, 0, []byte{0x01, 0x03, 0xE4, 0x40}, false) // 3 lines of bottom margin
+	if len(prints) != 1 {
+		t.Fatalf("Got %d prints after a bottom margin, want 1", len(prints))
+	}
+
+	img := prints[0]
+	if img.Bounds().Dx() != printerWidth || img.Bounds().Dy() != 32 {
+		t.Errorf("Got a %v print, want 160x32", img.Bounds().Size())
+	}
+	if got := img.GrayAt(0, 31).Y; got != 0xFF-0x55 {
+		t.Errorf("Got shade 0x%02X, want 0x%02X", got, 0xFF-0x55)
+	}
+
+	p.Flush()
+	if len(prints) != 1 {
+		t.Error("Flush with nothing printed called OnPrint")
+	}
+}
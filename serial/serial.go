@@ -0,0 +1,127 @@
+package serial
+
+// The bits of the serial control register (SC 0xFF02)
+const (
+	transferStart = 0x80 // set to start a transfer, cleared once it's done
+	fastClock     = 0x02 // CGB only, clock at 262144 Hz instead of 8192 Hz
+	internalClock = 0x01 // this side provides the clock, otherwise it waits for the other side
+)
+
+const (
+	bitCycles     = 512 // T-cycles per bit at 8192 Hz
+	fastBitCycles = 16  // T-cycles per bit at 262144 Hz
+	serialInt     = 3   // the serial interrupt request code
+)
+
+// Serial the serial port, shifts SB out a bit at a time while shifting in the
+// bits from the other side and raises the serial interrupt after 8 bits
+type Serial struct {
+	data             byte   // the byte being shifted out and in (SB 0xFF01)
+	control          byte   // the serial control (SC 0xFF02)
+	incoming         byte   // the byte being shifted in from the other side
+	bitsLeft         int    // the bits left in the current transfer (0 = not transferring)
+	cycles           int    // T-cycles until the next bit is shifted
+	CGBMode          bool   // whether the fast clock bit can be used
+	Device           Device // what's plugged into the serial port, nil if nothing is
+	requestInterrupt func(code byte)
+}
+
+// NewSerial create a new serial port with nothing connected
+func NewSerial(requestInterrupt func(code byte)) *Serial {
+	return &Serial{requestInterrupt: requestInterrupt}
+}
+
+// Read read the serial register at addr
+func (s *Serial) Read(addr uint16) byte {
+	switch addr {
+	case 0xFF01:
+		return s.data
+	case 0xFF02: // unused bits read as 1
+		if s.CGBMode {
+			return s.control | 0x7C
+		}
+		return s.control | 0x7E
+	}
+	return 0xFF
+}
+
+// Write write data to the serial register at addr
+func (s *Serial) Write(addr uint16, data byte) {
+	switch addr {
+	case 0xFF01:
+		s.data = data
+	case 0xFF02:
+		s.control = data & (transferStart | fastClock | internalClock)
+		if !s.CGBMode {
+			s.control &^= fastClock
+		}
+
+		s.bitsLeft = 0
+		if s.control&(transferStart|internalClock) == transferStart|internalClock {
+			s.startTransfer()
+		}
+	}
+}
+
+// startTransfer start clocking out SB, the byte from the other side is
+// exchanged straight away and shifted in as SB is shifted out
+// With nothing connected the bits shifted in are all 1
+func (s *Serial) startTransfer() {
+	s.bitsLeft = 8
+	s.cycles = s.bitCycles()
+	s.incoming = 0xFF
+	if s.Device != nil {
+		s.incoming = s.Device.Exchange(s.data)
+	}
+}
+
+// bitCycles get the T-cycles per bit for the selected clock speed
+func (s *Serial) bitCycles() int {
+	if s.control&fastClock != 0 {
+		return fastBitCycles
+	}
+	return bitCycles
+}
+
+// Tick tick the serial port by cycles amount of T-cycles
+func (s *Serial) Tick(cycles int) {
+	s.pollDevice()
+	if s.bitsLeft == 0 {
+		return
+	}
+
+	s.cycles -= cycles
+	for s.cycles <= 0 && s.bitsLeft > 0 {
+		s.bitsLeft--
+		s.data = s.data<<1 | (s.incoming>>s.bitsLeft)&0x01
+		s.cycles += s.bitCycles()
+	}
+
+	if s.bitsLeft == 0 {
+		s.finishTransfer()
+	}
+}
+
+// pollDevice let the device clock a transfer if it can, SB is swapped with
+// the devices byte straight away
+func (s *Serial) pollDevice() {
+	clock, ok := s.Device.(ExternalClock)
+	if !ok {
+		return
+	}
+
+	listening := s.control&(transferStart|internalClock) == transferStart
+	data, ok := clock.Poll(listening, s.data)
+	if !ok {
+		return
+	}
+
+	s.data = data
+	s.finishTransfer()
+}
+
+// finishTransfer clear the transfer bit and request the serial interrupt
+func (s *Serial) finishTransfer() {
+	s.control &^= transferStart
+	s.requestInterrupt(serialInt)
+}
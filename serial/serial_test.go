@@ -0,0 +1,125 @@
+package serial
+
+import "testing"
+
+// answeringDevice a device that always answers with the same byte
+type answeringDevice byte
+
+func (d answeringDevice) Exchange(data byte) byte {
+	return byte(d)
+}
+
+// clockingDevice a device that clocks a transfer with in the next time it's polled
+type clockingDevice struct {
+	in        byte
+	out       byte // what the game had in SB when the transfer happened
+	listening bool // whether the game was listening the last time it was polled
+}
+
+func (d *clockingDevice) Exchange(data byte) byte {
+	return 0xFF
+}
+
+func (d *clockingDevice) Poll(listening bool, out byte) (byte, bool) {
+	d.listening = listening
+	if !listening {
+		return 0, false
+	}
+	d.out = out
+	return d.in, true
+}
+
+// newTestSerial create a serial port that counts the serial interrupts it requests
+func newTestSerial(t *testing.T, interrupts *int) *Serial {
+	return NewSerial(func(code byte) {
+		if code != serialInt {
+			t.Errorf("Requested interrupt %d, want the serial interrupt (%d)", code, serialInt)
+		}
+		*interrupts++
+	})
+}
+
+func TestSerialInternalClock(t *testing.T) {
+	tests := []struct {
+		name      string
+		cgb       bool
+		control   byte
+		bitCycles int
+	}{
+		{"8192 Hz", false, 0x81, 512},
+		{"fast clock ignored on DMG", false, 0x83, 512},
+		{"fast clock on CGB", true, 0x83, 16},
+	}
+
+	for _, test := range tests {
+		interrupts := 0
+		s := newTestSerial(t, &interrupts)
+		s.CGBMode = test.cgb
+		s.Device = answeringDevice(0x5A)
+		s.Write(0xFF01, 0xA5)
+		s.Write(0xFF02, test.control)
+
+		s.Tick(test.bitCycles) // one bit, the msb of the answer (0) is shifted in
+		if got := s.Read(0xFF01); got != 0x4A {
+			t.Errorf("%s: SB is 0x%02X after one bit, want 0x4A", test.name, got)
+		}
+
+		s.Tick(test.bitCycles*7 - 4)
+		if interrupts != 0 || s.Read(0xFF02)&transferStart == 0 {
+			t.Errorf("%s: transfer finished before the 8th bit", test.name)
+		}
+
+		s.Tick(4)
+		if interrupts != 1 {
+			t.Errorf("%s: got %d interrupts after the 8th bit, want 1", test.name, interrupts)
+		}
+		if got := s.Read(0xFF01); got != 0x5A {
+			t.Errorf("%s: SB is 0x%02X after the transfer, want 0x5A", test.name, got)
+		}
+		if got := s.Read(0xFF02); got&transferStart != 0 {
+			t.Errorf("%s: SC is 0x%02X after the transfer, want the start bit cleared", test.name, got)
+		}
+
+		s.Tick(test.bitCycles * 8)
+		if interrupts != 1 {
+			t.Errorf("%s: got another interrupt with no transfer running", test.name)
+		}
+	}
+}
+
+func TestSerialNothingConnected(t *testing.T) {
+	interrupts := 0
+	s := newTestSerial(t, &interrupts)
+	s.Write(0xFF01, 0x12)
+	s.Write(0xFF02, 0x81)
+	s.Tick(bitCycles * 8)
+
+	if got := s.Read(0xFF01); got != 0xFF || interrupts != 1 {
+		t.Errorf("SB is 0x%02X with %d interrupts, want 0xFF with 1", got, interrupts)
+	}
+}
+
+func TestSerialExternalClock(t *testing.T) {
+	interrupts := 0
+	s := newTestSerial(t, &interrupts)
+	device := &clockingDevice{in: 0x34}
+	s.Device = device
+
+	s.Write(0xFF01, 0x12)
+	s.Tick(4)
+	if device.listening || interrupts != 0 {
+		t.Fatal("Transfer happened without the game waiting on the external clock")
+	}
+
+	s.Write(0xFF02, 0x80) // wait for the other side to clock
+	s.Tick(4)
+	if !device.listening || device.out != 0x12 {
+		t.Errorf("Device polled with listening %v and 0x%02X, want true and 0x12", device.listening, device.out)
+	}
+	if got := s.Read(0xFF01); got != 0x34 {
+		t.Errorf("SB is 0x%02X after the transfer, want 0x34", got)
+	}
+	if s.Read(0xFF02)&transferStart != 0 || interrupts != 1 {
+		t.Errorf("Transfer didn't finish, SC is 0x%02X with %d interrupts", s.Read(0xFF02), interrupts)
+	}
+}
@@ -0,0 +1,45 @@
+package sgb
+
+import "github.com/TheOrnyx/dmg-go/ppu"
+
+// drawBorder draw the 32x28 tile border from the tiles, map and palettes sent by the game
+// Each map entry has the tile number in bits 0-7, the palette (4-7) in bits 10-12
+// and the x and y flip in bits 14 and 15. Colour 0 is transparent and shows colour 0
+// of the screen palettes instead
+func (s *SGB) drawBorder() {
+	backdrop := s.palettes[0][0]
+
+	for i, entry := range s.borderMap {
+		tile := &s.borderTiles[entry&0xFF]
+		palette := &s.borderPalettes[(entry>>10)&0x03]
+		xFlip, yFlip := entry&0x4000 != 0, entry&0x8000 != 0
+		tileX, tileY := (i%32)*8, (i/32)*8
+
+		for row := range 8 {
+			tileRow := row
+			if yFlip {
+				tileRow = 7 - row
+			}
+			// 4bpp SNES tiles, planes 0 and 1 interleaved in the first 16 bytes, 2 and 3 in the last
+			planes := [4]byte{tile[tileRow*2], tile[tileRow*2+1], tile[16+tileRow*2], tile[17+tileRow*2]}
+
+			for col := range 8 {
+				bit := 7 - col
+				if xFlip {
+					bit = col
+				}
+
+				var colorNum byte
+				for p, plane := range planes {
+					colorNum |= ((plane >> bit) & 0x01) << p
+				}
+
+				pixel := ppu.Pixel{Color: colorNum, Opaque: colorNum != 0, CGB: true, RGB15: backdrop}
+				if colorNum != 0 {
+					pixel.RGB15 = palette[colorNum]
+				}
+				s.border[tileY+row][tileX+col] = pixel
+			}
+		}
+	}
+}
@@ -0,0 +1,275 @@
+package sgb
+
+// The SGB command codes (the top 5 bits of the first packet byte)
+// NOTE - the sound, icon, data, jump and OBJ_TRN commands aren't supported and are ignored
+const (
+	cmdPAL01   = 0x00
+	cmdPAL23   = 0x01
+	cmdPAL03   = 0x02
+	cmdPAL12   = 0x03
+	cmdATTRBLK = 0x04
+	cmdATTRLIN = 0x05
+	cmdATTRDIV = 0x06
+	cmdATTRCHR = 0x07
+	cmdPALSET  = 0x0A
+	cmdPALTRN  = 0x0B
+	cmdMLTREQ  = 0x11
+	cmdCHRTRN  = 0x13
+	cmdPCTTRN  = 0x14
+	cmdATTRTRN = 0x15
+	cmdATTRSET = 0x16
+	cmdMASKEN  = 0x17
+)
+
+// runCommand run the command in data (all of its packets joined together)
+func (s *SGB) runCommand(data []byte) {
+	switch data[0] >> 3 {
+	case cmdPAL01:
+		s.setPalettes(0, 1, data)
+	case cmdPAL23:
+		s.setPalettes(2, 3, data)
+	case cmdPAL03:
+		s.setPalettes(0, 3, data)
+	case cmdPAL12:
+		s.setPalettes(1, 2, data)
+	case cmdATTRBLK:
+		s.attrBlock(data)
+	case cmdATTRLIN:
+		s.attrLine(data)
+	case cmdATTRDIV:
+		s.attrDivide(data)
+	case cmdATTRCHR:
+		s.attrChr(data)
+	case cmdPALSET:
+		s.palSet(data)
+	case cmdMLTREQ:
+		s.multiplayer(data[1])
+	case cmdATTRSET:
+		s.applyAttrFile(data[1] & 0x3F)
+		if data[1]&0x40 != 0 {
+			s.mask = maskNone
+		}
+	case cmdMASKEN:
+		s.mask = data[1] & 0x03
+	case cmdPALTRN, cmdCHRTRN, cmdPCTTRN, cmdATTRTRN: // the data is read off the screen on the next frame
+		s.transfer = data[0] >> 3
+		s.transferArg = data[1]
+		s.transferPending = true
+	}
+}
+
+// readColor read the little endian 15-bit colour at offset in data
+func readColor(data []byte, offset int) uint16 {
+	return uint16(data[offset]) | uint16(data[offset+1])<<8
+}
+
+// setPalettes set colours 1-3 of palettes a and b and colour 0 of all the palettes (PAL01 - PAL12)
+func (s *SGB) setPalettes(a, b int, data []byte) {
+	s.setColor0(readColor(data, 1))
+	for i := range 3 {
+		s.palettes[a][i+1] = readColor(data, 3+i*2)
+		s.palettes[b][i+1] = readColor(data, 9+i*2)
+	}
+}
+
+// setColor0 set colour 0, which is shared by all the palettes and shows through the border
+func (s *SGB) setColor0(color uint16) {
+	for i := range s.palettes {
+		s.palettes[i][0] = color
+	}
+	s.borderDirty = true
+}
+
+// palSet copy 4 of the system palettes into the palettes and optionally apply an attribute file
+func (s *SGB) palSet(data []byte) {
+	for i := range s.palettes {
+		num := readColor(data, 1+i*2) & 0x01FF
+		s.palettes[i] = s.systemPalettes[num]
+	}
+	s.setColor0(s.palettes[0][0])
+
+	if data[9]&0x80 != 0 {
+		s.applyAttrFile(data[9] & 0x3F)
+	}
+	if data[9]&0x40 != 0 {
+		s.mask = maskNone
+	}
+}
+
+// attrBlock set the palettes inside, on the edge of and outside of rectangles of areas (ATTR_BLK)
+// Each data set is 6 bytes: which parts to change, their palettes then X1, Y1, X2, Y2
+func (s *SGB) attrBlock(data []byte) {
+	for i := range int(data[1]) {
+		offset := 2 + i*6
+		if offset+6 > len(data) {
+			break
+		}
+		set := data[offset : offset+6]
+
+		control := set[0] & 0x07
+		inside, edge, outside := set[1]&0x03, (set[1]>>2)&0x03, (set[1]>>4)&0x03
+		switch control { // changing only the inside or outside changes the edge too
+		case 0x01:
+			control, edge = 0x03, inside
+		case 0x04:
+			control, edge = 0x06, outside
+		}
+		x1, y1, x2, y2 := int(set[2]), int(set[3]), int(set[4]), int(set[5])
+
+		for y := range s.attributes {
+			for x := range s.attributes[y] {
+				switch {
+				case x > x1 && x < x2 && y > y1 && y < y2:
+					if control&0x01 != 0 {
+						s.attributes[y][x] = inside
+					}
+				case x < x1 || x > x2 || y < y1 || y > y2:
+					if control&0x04 != 0 {
+						s.attributes[y][x] = outside
+					}
+				default:
+					if control&0x02 != 0 {
+						s.attributes[y][x] = edge
+					}
+				}
+			}
+		}
+	}
+}
+
+// attrLine set the palette of whole rows or columns of areas (ATTR_LIN)
+// Each byte is the line number, the palette and whether it's a row
+func (s *SGB) attrLine(data []byte) {
+	for i := range int(data[1]) {
+		if 2+i >= len(data) {
+			break
+		}
+		line, palette, horizontal := int(data[2+i]&0x1F), (data[2+i]>>5)&0x03, data[2+i]&0x80 != 0
+
+		switch {
+		case horizontal && line < len(s.attributes):
+			for x := range s.attributes[line] {
+				s.attributes[line][x] = palette
+			}
+		case !horizontal && line < len(s.attributes[0]):
+			for y := range s.attributes {
+				s.attributes[y][line] = palette
+			}
+		}
+	}
+}
+
+// attrDivide split the screen in two with a line, setting a palette for either side and the line (ATTR_DIV)
+func (s *SGB) attrDivide(data []byte) {
+	after, before, on := data[1]&0x03, (data[1]>>2)&0x03, (data[1]>>4)&0x03
+	horizontal := data[1]&0x40 != 0
+	pos := int(data[2])
+
+	for y := range s.attributes {
+		for x := range s.attributes[y] {
+			coord := x
+			if horizontal {
+				coord = y
+			}
+
+			switch {
+			case coord < pos:
+				s.attributes[y][x] = before
+			case coord == pos:
+				s.attributes[y][x] = on
+			default:
+				s.attributes[y][x] = after
+			}
+		}
+	}
+}
+
+// attrChr set the palette of areas one by one starting at X, Y going across or down (ATTR_CHR)
+// The palettes are packed 4 to a byte starting from the top bits
+func (s *SGB) attrChr(data []byte) {
+	x, y := int(data[1]), int(data[2])
+	count := min(int(readColor(data, 3)), 360)
+	vertical := data[5]&0x01 != 0
+
+	for i := range count {
+		if 6+i/4 >= len(data) {
+			break
+		}
+		palette := (data[6+i/4] >> (6 - (i%4)*2)) & 0x03
+		if x < 20 && y < 18 {
+			s.attributes[y][x] = palette
+		}
+
+		if vertical {
+			if y++; y >= 18 {
+				y, x = 0, (x+1)%20
+			}
+		} else {
+			if x++; x >= 20 {
+				x, y = 0, (y+1)%18
+			}
+		}
+	}
+}
+
+// applyAttrFile set the palette of every area from one of the attribute files sent with ATTR_TRN
+func (s *SGB) applyAttrFile(num byte) {
+	if int(num) >= len(s.attrFiles) {
+		return
+	}
+
+	file := &s.attrFiles[num]
+	for i := range 20 * 18 {
+		s.attributes[i/20][i%20] = (file[i/4] >> (6 - (i%4)*2)) & 0x03
+	}
+}
+
+// multiplayer set the number of joypads (MLT_REQ), 0 = 1, 1 = 2 and 3 = 4
+func (s *SGB) multiplayer(mode byte) {
+	switch mode & 0x03 {
+	case 0x01:
+		s.joypad.SetPlayers(2)
+	case 0x03:
+		s.joypad.SetPlayers(4)
+	default:
+		s.joypad.SetPlayers(1)
+	}
+}
+
+// runTransfer read the 4KB being shown on screen for the waiting VRAM transfer command
+func (s *SGB) runTransfer() {
+	data := s.ppu.TransferTiles()
+
+	switch s.transfer {
+	case cmdPALTRN:
+		for i := range s.systemPalettes {
+			for c := range 4 {
+				s.systemPalettes[i][c] = readColor(data[:], i*8+c*2)
+			}
+		}
+
+	case cmdATTRTRN:
+		for i := range s.attrFiles {
+			copy(s.attrFiles[i][:], data[i*90:])
+		}
+
+	case cmdCHRTRN: // either the first or second half of the tiles
+		first := int(s.transferArg&0x01) * 128
+		for i := range 128 {
+			copy(s.borderTiles[first+i][:], data[i*32:])
+		}
+		s.borderDirty = true
+
+	case cmdPCTTRN: // the tile map followed by the palettes at 0x800
+		for i := range s.borderMap {
+			s.borderMap[i] = readColor(data[:], i*2)
+		}
+		for p := range s.borderPalettes {
+			for c := range 16 {
+				s.borderPalettes[p][c] = readColor(data[:], 0x800+p*32+c*2)
+			}
+		}
+		s.hasBorder = true
+		s.borderDirty = true
+	}
+}
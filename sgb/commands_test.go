@@ -0,0 +1,138 @@
+package sgb
+
+import "testing"
+
+// command pad a command out to a whole packet
+func command(data ...byte) []byte {
+	packet := make([]byte, 16)
+	copy(packet, data)
+	return packet
+}
+
+func TestPAL01(t *testing.T) {
+	s, _ := newTestSGB(true)
+	s.runCommand(command(cmdPAL01<<3|1,
+		0x11, 0x11, // colour 0 for every palette
+		0x01, 0x00, 0x02, 0x00, 0x03, 0x00, // palette 0
+		0x11, 0x00, 0x12, 0x00, 0x13, 0x00, // palette 1
+	))
+
+	want := [4][4]uint16{
+		{0x1111, 0x0001, 0x0002, 0x0003},
+		{0x1111, 0x0011, 0x0012, 0x0013},
+		{0x1111, defaultPalette[1], defaultPalette[2], defaultPalette[3]},
+		{0x1111, defaultPalette[1], defaultPalette[2], defaultPalette[3]},
+	}
+	if s.palettes != want {
+		t.Errorf("Got palettes %04X, want %04X", s.palettes, want)
+	}
+}
+
+func TestATTRBLK(t *testing.T) {
+	tests := []struct {
+		name                  string
+		control, palettes     byte
+		inside, edge, outside byte
+	}{
+		{"all", 0x07, 0x39, 1, 2, 3},
+		{"inside changes the edge", 0x01, 0x01, 1, 1, 0},
+		{"outside changes the edge", 0x04, 0x30, 0, 3, 3},
+		{"edge", 0x02, 0x08, 0, 2, 0},
+		{"inside and outside", 0x05, 0x31, 1, 0, 3},
+	}
+
+	for _, test := range tests {
+		s, _ := newTestSGB(true)
+		s.runCommand(command(cmdATTRBLK<<3|1, 1, test.control, test.palettes, 5, 4, 10, 9))
+
+		got := [3]byte{s.attributes[6][7], s.attributes[4][5], s.attributes[2][2]}
+		want := [3]byte{test.inside, test.edge, test.outside}
+		if got != want {
+			t.Errorf("%s: got inside, edge and outside %v, want %v", test.name, got, want)
+		}
+		if s.attributes[9][10] != test.edge || s.attributes[10][10] != test.outside {
+			t.Errorf("%s: the bottom right corner isn't part of the edge", test.name)
+		}
+	}
+}
+
+func TestATTRLIN(t *testing.T) {
+	s, _ := newTestSGB(true)
+	s.runCommand(command(cmdATTRLIN<<3|1, 3,
+		0x80|1<<5|17, // row 17 palette 1
+		2<<5|19,      // column 19 palette 2
+		0x80|3<<5|25, // row 25 is off the screen
+	))
+
+	if s.attributes[17][0] != 1 || s.attributes[17][19] != 2 || s.attributes[0][19] != 2 {
+		t.Errorf("Got row 17 %v and column 19 ending %d", s.attributes[17], s.attributes[0][19])
+	}
+	if s.attributes[16][0] != 0 {
+		t.Error("Line off the screen changed the attributes")
+	}
+}
+
+func TestATTRDIV(t *testing.T) {
+	s, _ := newTestSGB(true)
+	s.runCommand(command(cmdATTRDIV<<3|1, 0x40|2<<4|1<<2|3, 9)) // split at row 9
+
+	for _, test := range []struct{ y, want int }{{0, 1}, {8, 1}, {9, 2}, {10, 3}, {17, 3}} {
+		if got := s.attributes[test.y][5]; int(got) != test.want {
+			t.Errorf("Row %d has palette %d, want %d", test.y, got, test.want)
+		}
+	}
+
+	s.runCommand(command(cmdATTRDIV<<3|1, 2<<4|1<<2|3, 4)) // split at column 4
+	if s.attributes[0][3] != 1 || s.attributes[0][4] != 2 || s.attributes[0][5] != 3 {
+		t.Errorf("Got columns 3-5 %v, want [1 2 3]", s.attributes[0][3:6])
+	}
+}
+
+func TestATTRCHR(t *testing.T) {
+	type area struct{ x, y int }
+	tests := []struct {
+		name     string
+		x, y     byte
+		vertical byte
+		want     [3]area // the areas given palettes 1, 2 and 3
+	}{
+		{"across wraps to the next row", 19, 0, 0, [3]area{{19, 0}, {0, 1}, {1, 1}}},
+		{"down wraps to the next column", 0, 17, 1, [3]area{{0, 17}, {1, 0}, {1, 1}}},
+		{"last area wraps to the first", 19, 17, 0, [3]area{{19, 17}, {0, 0}, {1, 0}}},
+		{"last column down wraps to the first", 19, 17, 1, [3]area{{19, 17}, {0, 0}, {0, 1}}},
+	}
+
+	for _, test := range tests {
+		s, _ := newTestSGB(true)
+		s.runCommand(command(cmdATTRCHR<<3|1, test.x, test.y, 3, 0, test.vertical, 0x6C)) // palettes 1, 2, 3
+
+		for i, a := range test.want {
+			if got := s.attributes[a.y][a.x]; got != byte(i+1) {
+				t.Errorf("%s: area %d,%d has palette %d, want %d", test.name, a.x, a.y, got, i+1)
+			}
+		}
+	}
+}
+
+func TestMLTREQ(t *testing.T) {
+	tests := []struct {
+		mode    byte
+		players int
+	}{{0x00, 1}, {0x01, 2}, {0x02, 1}, {0x03, 4}}
+
+	for _, test := range tests {
+		s, j := newTestSGB(true)
+		s.runCommand(command(cmdMLTREQ<<3|1, test.mode))
+
+		// P15 going high moves to the next joypad, whose ID is read with both lines high
+		seen := map[byte]bool{}
+		for range 8 {
+			j.WriteData(0x30)
+			seen[j.Read()] = true
+			j.WriteData(0x10)
+		}
+		if len(seen) != test.players {
+			t.Errorf("MLT_REQ %d: got %d joypads, want %d", test.mode, len(seen), test.players)
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package sgb
+
+import (
+	"github.com/TheOrnyx/dmg-go/joypad"
+	"github.com/TheOrnyx/dmg-go/ppu"
+)
+
+// The screen masks set by MASK_EN
+const (
+	maskNone   = 0 // show the screen normally
+	maskFreeze = 1 // keep showing the last frame
+	maskBlack  = 2 // show a black screen
+	maskColor0 = 3 // fill the screen with colour 0
+)
+
+// defaultPalette the colours all 4 palettes start with before the game sets any
+var defaultPalette = [4]uint16{0x67BF, 0x265B, 0x10B5, 0x2866}
+
+// SGB the Super Game Boy, it receives command packets through the joypad
+// register and colours each 8x8 area of the screen with one of 4 palettes
+type SGB struct {
+	ppu    *ppu.PPU
+	joypad *joypad.Joypad
+	active bool // whether the cart supports the SGB, commands are ignored if not
+
+	// receiving packets
+	receiving bool // whether a reset pulse started a packet
+	waitHigh  bool // whether both lines need to go high before the next bit
+	bitCount  int  // the number of bits of the packet received
+	packet    [16]byte
+	command   []byte // the packets received so far for the current command
+
+	palettes       [4][4]uint16        // the palettes used to colour the screen
+	systemPalettes [512][4]uint16      // the palettes sent with PAL_TRN for PAL_SET to pick from
+	attributes     [18][20]byte        // the palette for each 8x8 area of the screen
+	attrFiles      [45][90]byte        // the attribute files sent with ATTR_TRN, 2 bits per area
+	mask           byte                // the screen mask set by MASK_EN
+	lastFrame      [144][160]ppu.Pixel // the last coloured frame, shown while the screen is frozen
+
+	transfer        byte // the VRAM transfer command waiting for the next frame
+	transferArg     byte // the first data byte of the transfer command
+	transferPending bool
+
+	borderTiles    [256][32]byte   // the 4bpp border tiles sent with CHR_TRN
+	borderMap      [32 * 28]uint16 // the border tile map sent with PCT_TRN
+	borderPalettes [4][16]uint16   // the border palettes (4-7) sent with PCT_TRN
+	hasBorder      bool
+	borderDirty    bool // whether the border has to be redrawn
+	border         [224][256]ppu.Pixel
+}
+
+// NewSGB create a new SGB and hook it up to the joypad and ppu
+// active is whether the cart supports the SGB
+func NewSGB(p *ppu.PPU, j *joypad.Joypad, active bool) *SGB {
+	sgb := &SGB{ppu: p, joypad: j, active: active}
+	for i := range sgb.palettes {
+		sgb.palettes[i] = defaultPalette
+	}
+
+	j.OnWrite = sgb.WriteJoypad
+	p.OnVBlank = sgb.endFrame
+	return sgb
+}
+
+// WriteJoypad receive a write to the joypad register
+// A packet starts with both lines going low (reset pulse), then each of the 128
+// bits is P15 low for a 1 or P14 low for a 0 with both lines going high in
+// between. It ends with a 0 stop bit
+func (s *SGB) WriteJoypad(data byte) {
+	lines := data & 0x30
+	switch lines {
+	case 0x00:
+		s.receiving = true
+		s.waitHigh = true
+		s.bitCount = 0
+		s.packet = [16]byte{}
+
+	case 0x30:
+		s.waitHigh = false
+
+	default:
+		if !s.receiving || s.waitHigh {
+			return
+		}
+		s.waitHigh = true
+
+		if s.bitCount == 128 {
+			s.receiving = false
+			if lines == 0x20 {
+				s.receivePacket()
+			}
+			return
+		}
+
+		if lines == 0x10 {
+			s.packet[s.bitCount/8] |= 1 << (s.bitCount % 8)
+		}
+		s.bitCount++
+	}
+}
+
+// receivePacket add a finished packet to the command and run it once all of
+// its packets are in, the first byte is the command * 8 + the packet count
+func (s *SGB) receivePacket() {
+	if !s.active {
+		return
+	}
+
+	if len(s.command) == 0 && s.packet[0]&0x07 == 0 {
+		return
+	}
+
+	s.command = append(s.command, s.packet[:]...)
+	if len(s.command)/16 >= int(s.command[0]&0x07) {
+		s.runCommand(s.command)
+		s.command = nil
+	}
+}
+
+// endFrame run any waiting VRAM transfer and colour the finished frame
+func (s *SGB) endFrame() {
+	if s.transferPending {
+		s.transferPending = false
+		s.runTransfer()
+	}
+
+	if s.hasBorder {
+		if s.borderDirty {
+			s.drawBorder()
+			s.borderDirty = false
+		}
+		s.ppu.Screen.Border = &s.border
+	}
+
+	s.colorScreen()
+}
+
+// colorScreen colour the finished frame using the palette for each area,
+// or replace it if the screen is masked
+func (s *SGB) colorScreen() {
+	screen := &s.ppu.Screen.FinalScreen
+
+	switch s.mask {
+	case maskFreeze:
+		*screen = s.lastFrame
+		return
+	case maskBlack, maskColor0:
+		var color uint16 // black
+		if s.mask == maskColor0 {
+			color = s.palettes[0][0]
+		}
+		for y := range screen {
+			for x := range screen[y] {
+				screen[y][x] = ppu.Pixel{Opaque: true, CGB: true, RGB15: color}
+			}
+		}
+		return
+	}
+
+	for y := range screen {
+		for x := range screen[y] {
+			pixel := &screen[y][x]
+			pixel.CGB = true
+			pixel.RGB15 = s.palettes[s.attributes[y/8][x/8]][pixel.Color&0x03]
+		}
+	}
+	s.lastFrame = *screen
+}
@@ -0,0 +1,137 @@
+package sgb
+
+import (
+	"testing"
+
+	"github.com/TheOrnyx/dmg-go/joypad"
+	"github.com/TheOrnyx/dmg-go/ppu"
+	"github.com/TheOrnyx/dmg-go/timer"
+)
+
+// newTestSGB create an SGB hooked up to its own joypad and ppu
+func newTestSGB(active bool) (*SGB, *joypad.Joypad) {
+	noInterrupt := func(code byte) {}
+	j := joypad.NewJoypad(noInterrupt)
+	p := ppu.NewPPU(timer.NewTimer(noInterrupt), noInterrupt)
+	return NewSGB(p, j, active), j
+}
+
+// sendBits write bits to the joypad register the way the game sends them, P15
+// low for a 1 and P14 low for a 0 with both lines going high after each
+func sendBits(j *joypad.Joypad, bits ...bool) {
+	for _, bit := range bits {
+		if bit {
+			j.WriteData(0x10)
+		} else {
+			j.WriteData(0x20)
+		}
+		j.WriteData(0x30)
+	}
+}
+
+// packetBits the 128 bits of packet, lsb of the first byte first
+func packetBits(packet [16]byte) []bool {
+	bits := make([]bool, 0, 128)
+	for _, b := range packet {
+		for i := range 8 {
+			bits = append(bits, b>>i&0x01 != 0)
+		}
+	}
+	return bits
+}
+
+// sendPacket send packet with a reset pulse before it and a 0 stop bit after
+func sendPacket(j *joypad.Joypad, packet [16]byte) {
+	j.WriteData(0x00)
+	j.WriteData(0x30)
+	sendBits(j, packetBits(packet)...)
+	sendBits(j, false)
+}
+
+// maskPacket a MASK_EN packet setting the mask to mask
+func maskPacket(mask byte) [16]byte {
+	return [16]byte{cmdMASKEN<<3 | 1, mask}
+}
+
+func TestReceivePacket(t *testing.T) {
+	s, j := newTestSGB(true)
+	packet := [16]byte{0x81, 0x01, 0x80, 0x55, 0xAA, 15: 0xC3}
+	sendPacket(j, packet)
+
+	if s.packet != packet {
+		t.Errorf("Received % X, want % X", s.packet, packet)
+	}
+	if s.receiving {
+		t.Error("Still receiving after the stop bit")
+	}
+}
+
+func TestReceivePacketFraming(t *testing.T) {
+	tests := []struct {
+		name string
+		send func(j *joypad.Joypad)
+		want byte // the mask after sending
+	}{
+		{"packet", func(j *joypad.Joypad) { sendPacket(j, maskPacket(maskBlack)) }, maskBlack},
+		{"no reset pulse", func(j *joypad.Joypad) {
+			sendBits(j, packetBits(maskPacket(maskBlack))...)
+			sendBits(j, false)
+		}, maskNone},
+		{"stop bit is 1", func(j *joypad.Joypad) {
+			j.WriteData(0x00)
+			j.WriteData(0x30)
+			sendBits(j, packetBits(maskPacket(maskBlack))...)
+			sendBits(j, true)
+		}, maskNone},
+		{"bit held without going high", func(j *joypad.Joypad) {
+			j.WriteData(0x00)
+			j.WriteData(0x30)
+			j.WriteData(0x10) // an extra 1 that doesn't count without both lines going high first
+			sendBits(j, packetBits(maskPacket(maskBlack))...)
+			sendBits(j, false)
+		}, maskBlack},
+		{"reset pulse restarts the packet", func(j *joypad.Joypad) {
+			j.WriteData(0x00)
+			j.WriteData(0x30)
+			sendBits(j, true, true, true)
+			sendPacket(j, maskPacket(maskColor0))
+		}, maskColor0},
+	}
+
+	for _, test := range tests {
+		s, j := newTestSGB(true)
+		test.send(j)
+		if s.mask != test.want {
+			t.Errorf("%s: got mask %d, want %d", test.name, s.mask, test.want)
+		}
+	}
+}
+
+func TestReceivePacketInactive(t *testing.T) {
+	s, j := newTestSGB(false)
+	sendPacket(j, maskPacket(maskBlack))
+	if s.mask != maskNone {
+		t.Error("Cart without SGB support ran a command")
+	}
+}
+
+func TestReceiveMultiplePackets(t *testing.T) {
+	s, j := newTestSGB(true)
+
+	// ATTR_BLK with 3 data sets takes 2 packets, the third set (the whole
+	// screen inside palette 1) starts at byte 14 and carries on into the second
+	first := [16]byte{cmdATTRBLK<<3 | 2, 3, 14: 0x01, 15: 0x01}
+	second := [16]byte{0, 0, 19, 17}
+
+	sendPacket(j, first)
+	if len(s.command) != 16 || s.attributes[9][9] != 0 {
+		t.Fatalf("Got %d bytes of command after the first packet, want 16 and nothing run", len(s.command))
+	}
+	sendPacket(j, second)
+	if s.command != nil {
+		t.Fatal("Command didn't run after its last packet")
+	}
+	if s.attributes[0][0] != 1 || s.attributes[9][9] != 1 {
+		t.Error("Data set split across the packets wasn't applied")
+	}
+}
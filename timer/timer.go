@@ -19,7 +19,7 @@ type Timer struct {
 	tac                    byte            // timer control - controls behaviour of the TIMA reg
 	lastBit                uint16          // the last and result of the chose DIV value and the timer enable bit, used to detect falling edge
 	requestInterrupt       func(code byte) // interrupt request func
-	doubleSpeed            bool            // whether or not the timer is running on double speed - TODO IMPLEMENT
+	doubleSpeed            bool            // whether or not the CGB is running on double speed
 	timaReload             bool            // whether or not you're in the process of reloading the TIMA
 	cyclesTilTIMAInterrupt int             // number of cycles until the TIMA IRQ interrupt flag is raised
 }
@@ -152,7 +152,20 @@ func (t *Timer) Read(addr uint16) byte {
 	}
 }
 
+// DoubleSpeed return whether the CGB is running on double speed
+func (t *Timer) DoubleSpeed() bool {
+	return t.doubleSpeed
+}
+
+// SetDoubleSpeed switch the CGB between double and normal speed
+// Switching speed resets DIV like on hardware
+func (t *Timer) SetDoubleSpeed(enabled bool) {
+	t.doubleSpeed = enabled
+	t.changeDiv(0)
+}
+
 // TacInfo get the info from the tac bits for whether TIMA is enabled and what the clockspeed is
+// The timer is clocked by the CPU so runs twice as fast in double speed
 func (t *Timer) TacInfo() (enabled bool, speed int) {
 	enabled = t.tac&0x04 != 0
 	speedBits := t.tac & 0x03
@@ -171,6 +184,10 @@ func (t *Timer) TacInfo() (enabled bool, speed int) {
 		speed = ClockSpeed / 256
 	}
 
+	if t.doubleSpeed {
+		speed *= 2
+	}
+
 	return enabled, speed
 }
 
@@ -0,0 +1,186 @@
+package window
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/TheOrnyx/dmg-go/config"
+	"github.com/TheOrnyx/dmg-go/joypad"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// controller an open game controller and its bindings
+type controller struct {
+	pad      *sdl.GameController
+	buttons  [8][]sdl.GameControllerButton
+	hasStick bool
+	stickX   sdl.GameControllerAxis
+	stickY   sdl.GameControllerAxis
+	deadzone int16
+	noRumble bool // set once rumbling fails so it's only logged once
+	hasTilt  bool // whether a stick tilts MBC7 carts
+	tiltX    sdl.GameControllerAxis
+	tiltY    sdl.GameControllerAxis
+}
+
+// controllerSet the currently connected controllers keyed by their instance id
+type controllerSet map[sdl.JoystickID]*controller
+
+// handleEvent open or close controllers as they're plugged in and out
+// using the bindings from cfg
+func (cs controllerSet) handleEvent(event *sdl.ControllerDeviceEvent, cfg *config.Config) {
+	switch event.Type {
+	case sdl.CONTROLLERDEVICEADDED: // Which is the device index here
+		ctrl, err := openController(int(event.Which), cfg)
+		if err != nil {
+			log.Println("Failed to open controller:", err)
+			return
+		}
+		cs[ctrl.pad.Joystick().InstanceID()] = ctrl
+
+	case sdl.CONTROLLERDEVICEREMOVED: // Which is the instance id here
+		if ctrl, found := cs[event.Which]; found {
+			log.Printf("Controller disconnected: %s\n", ctrl.pad.Name())
+			ctrl.pad.Close()
+			delete(cs, event.Which)
+		}
+	}
+}
+
+// readButtons add the state of the controller buttons on top of inputs
+func (cs controllerSet) readButtons(inputs *[8]bool) {
+	for _, ctrl := range cs {
+		ctrl.readButtons(inputs)
+	}
+}
+
+// rumble turn the rumble on or off on all the controllers
+func (cs controllerSet) rumble(on bool) {
+	for _, ctrl := range cs {
+		ctrl.rumble(on)
+	}
+}
+
+// closeAll close all the open controllers
+func (cs controllerSet) closeAll() {
+	for id, ctrl := range cs {
+		ctrl.pad.Close()
+		delete(cs, id)
+	}
+}
+
+// openController open the controller at device index and load its bindings from cfg
+func openController(index int, cfg *config.Config) (*controller, error) {
+	pad := sdl.GameControllerOpen(index)
+	if pad == nil {
+		return nil, sdl.GetError()
+	}
+
+	guid := sdl.JoystickGetGUIDString(pad.Joystick().GUID())
+	ctrl, err := newController(pad, cfg.ControllerFor(guid))
+	if err != nil {
+		pad.Close()
+		return nil, fmt.Errorf("Bad bindings for controller %s: %v", guid, err)
+	}
+
+	log.Printf("Controller connected: %s (GUID %s)\n", pad.Name(), guid)
+	return ctrl, nil
+}
+
+// newController create a controller for pad using the bindings in cfg
+func newController(pad *sdl.GameController, cfg config.ControllerConfig) (*controller, error) {
+	ctrl := &controller{pad: pad, deadzone: int16(cfg.Deadzone)}
+
+	for name, padButtons := range cfg.Buttons {
+		button, found := config.ButtonNames[name]
+		if !found {
+			return nil, fmt.Errorf("Unknown button %q", name)
+		}
+
+		for _, padButton := range padButtons {
+			code := sdl.GameControllerGetButtonFromString(padButton)
+			if code == sdl.CONTROLLER_BUTTON_INVALID {
+				return nil, fmt.Errorf("Unknown controller button %q", padButton)
+			}
+			ctrl.buttons[button] = append(ctrl.buttons[button], code)
+		}
+	}
+
+	ctrl.hasStick, ctrl.stickX, ctrl.stickY = stickAxes(cfg.Stick)
+	ctrl.hasTilt, ctrl.tiltX, ctrl.tiltY = stickAxes(cfg.TiltStick)
+
+	return ctrl, nil
+}
+
+// stickAxes get the axes for the stick called name, ok is false if it's "none" or empty
+func stickAxes(name string) (ok bool, x, y sdl.GameControllerAxis) {
+	switch name {
+	case "left":
+		return true, sdl.CONTROLLER_AXIS_LEFTX, sdl.CONTROLLER_AXIS_LEFTY
+	case "right":
+		return true, sdl.CONTROLLER_AXIS_RIGHTX, sdl.CONTROLLER_AXIS_RIGHTY
+	}
+	return false, 0, 0
+}
+
+// readButtons add the state of the controller's buttons and stick on top of inputs
+func (c *controller) readButtons(inputs *[8]bool) {
+	for button, codes := range c.buttons {
+		for _, code := range codes {
+			if c.pad.Button(code) == 1 {
+				inputs[button] = true
+			}
+		}
+	}
+
+	if !c.hasStick {
+		return
+	}
+
+	x, y := c.pad.Axis(c.stickX), c.pad.Axis(c.stickY)
+	if x > c.deadzone {
+		inputs[joypad.DpadRight] = true
+	} else if x < -c.deadzone {
+		inputs[joypad.DpadLeft] = true
+	}
+	if y > c.deadzone { // down is positive
+		inputs[joypad.DpadDown] = true
+	} else if y < -c.deadzone {
+		inputs[joypad.DpadUp] = true
+	}
+}
+
+// rumble turn the controller's rumble on or off, it's kept on for rumbleDuration
+// at a time so a stuck motor stops on its own if the game stops updating it
+func (c *controller) rumble(on bool) {
+	if c.noRumble {
+		return
+	}
+
+	var strength uint16
+	var duration uint32
+	if on {
+		strength, duration = 0xFFFF, rumbleDuration
+	}
+
+	if err := c.pad.Rumble(strength, strength, duration); err != nil {
+		log.Printf("Controller %s can't rumble: %v\n", c.pad.Name(), err)
+		c.noRumble = true
+	}
+}
+
+// tilt get the tilt from the first controller with its tilt stick pushed past the deadzone
+func (cs controllerSet) tilt() (x, y float64, ok bool) {
+	for _, ctrl := range cs {
+		if !ctrl.hasTilt {
+			continue
+		}
+
+		ax, ay := int(ctrl.pad.Axis(ctrl.tiltX)), int(ctrl.pad.Axis(ctrl.tiltY))
+		deadzone := int(ctrl.deadzone)
+		if max(ax, -ax) > deadzone || max(ay, -ay) > deadzone {
+			return float64(ax) / 32767, float64(ay) / 32767, true
+		}
+	}
+	return 0, 0, false
+}
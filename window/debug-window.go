@@ -3,7 +3,6 @@ package window
 import (
 	"log"
 
-	"github.com/TheOrnyx/dmg-go/joypad"
 	"github.com/TheOrnyx/dmg-go/ppu"
 	"github.com/veandco/go-sdl2/sdl"
 )
@@ -11,21 +10,27 @@ import (
 type DebugWindow struct {
 	Window *sdl.Window
 	Renderer *sdl.Renderer
+	Texture *sdl.Texture // the streaming texture holding all four layers
 	midX int32
 	midY int32
 	width int32
 	height int32
+	hotkeys hotkeyState
+	bindings *Bindings
+	controllers controllerSet // the connected game controllers
 }
 
 // StartSDLWindowSystem initialize and start running the sdl windowsystem
-func CreateDebugWindow(width, height, scale int32) Screen {
+func CreateDebugWindow(width, height, scale int32, bindings *Bindings) Screen {
 	d := new(DebugWindow)
+	d.bindings = bindings
+	d.controllers = make(controllerSet)
 
 	if err := sdl.Init(sdl.INIT_EVERYTHING); err != nil {
 		FatalLog.Println("Failed to Init SDL: ", err)
 	}
 
-	win, err := sdl.CreateWindow("Gameboy-Golor debug", sdl.WINDOWPOS_UNDEFINED, sdl.WINDOWPOS_UNDEFINED, width, height, sdl.WINDOW_SHOWN)
+	win, err := sdl.CreateWindow("Gameboy-Golor debug", sdl.WINDOWPOS_UNDEFINED, sdl.WINDOWPOS_UNDEFINED, width, height, sdl.WINDOW_SHOWN|sdl.WINDOW_RESIZABLE)
 	if err != nil {
 		FatalLog.Println("Failed to Create SDL window: ", err)
 	}
@@ -36,13 +41,15 @@ func CreateDebugWindow(width, height, scale int32) Screen {
 		FatalLog.Println("Failed to creeate SDL renderer: ", err)
 	}
 	d.Renderer = renderer
-	if err = d.Renderer.SetScale(float32(scale)/2, float32(scale)/2); err != nil {
-		log.Println("Failed to scale up Renderer, continuing anyway but might not function")
-	}
 
-	d.width, d.height = width/2, height/2
-	d.midX, d.midY = d.width/2, d.height/2
+	d.width, d.height = gbScreenWidth*2, gbScreenHeight*2
+	d.midX, d.midY = gbScreenWidth, gbScreenHeight
 
+	texture, err := newScreenTexture(d.Renderer, d.width, d.height)
+	if err != nil {
+		FatalLog.Println("Failed to create SDL screen texture: ", err)
+	}
+	d.Texture = texture
 
 	return d
 }
@@ -54,72 +61,61 @@ func (d *DebugWindow) ClearScreen() {
 }
 
 // DebugRender render the debug screen with each layer view
+// Background in the top left, window in the top right, objects in the
+// bottom left and the final screen in the bottom right
 func (d *DebugWindow) RenderScreen(screen *ppu.Screen)  {
-	
-	// Background layer
-	for drawY := 0; drawY < int(d.midY); drawY++ { 
-		for drawX := 0; drawX < int(d.midX); drawX++ {
-			d.Renderer.SetDrawColor(colorsFromSDLCol(mainPalette[screen.Background[drawY][drawX].Color]))
-			d.Renderer.DrawPoint(int32(drawX), int32(drawY))
-		}
-	}
-
-	// Window layer
-	for drawY := 0; drawY < int(d.midY); drawY++ {
-		for drawX := d.midX; drawX < d.width; drawX++ {
-			d.Renderer.SetDrawColor(colorsFromSDLCol(mainPalette[screen.Window[drawY][drawX - d.midX].Color]))
-			d.Renderer.DrawPoint(int32(drawX), int32(drawY))
-		}
-	}
-
-	for drawY := d.midY; drawY < d.height; drawY++ {
-		for drawX := 0; drawX < int(d.midX); drawX++ {
-			d.Renderer.SetDrawColor(colorsFromSDLCol(mainPalette[screen.Objects[drawY - d.midY][drawX].Color]))
-			d.Renderer.DrawPoint(int32(drawX), int32(drawY))
-		}
-	}
-
-	for drawY := d.midY; drawY < d.height; drawY++ {
-		for drawX := d.midX; drawX < d.width; drawX++ {
-			d.Renderer.SetDrawColor(colorsFromSDLCol(mainPalette[screen.FinalScreen[drawY - d.midY][drawX - d.midX].Color]))
-			d.Renderer.DrawPoint(int32(drawX), int32(drawY))
-		}
+	pixels, pitch, err := d.Texture.Lock(nil)
+	if err != nil {
+		log.Println("Failed to lock screen texture:", err)
+		return
 	}
-
+	palette := ActivePalette()
+	drawLayer(pixels, pitch, 0, 0, &screen.Background, palette)
+	drawLayer(pixels, pitch, int(d.midX), 0, &screen.Window, palette)
+	drawLayer(pixels, pitch, 0, int(d.midY), &screen.Objects, palette)
+	drawLayer(pixels, pitch, int(d.midX), int(d.midY), &screen.FinalScreen, palette)
+	drawPaletteName(pixels, pitch, int(d.midX), int(d.midY))
+	d.Texture.Unlock()
+
+	d.Renderer.Copy(d.Texture, nil, screenRect(d.Renderer, d.width, d.height, IntegerScaling))
 	d.Renderer.Present()
 }
 
 // CloseScreen shut down the screen
 func (d *DebugWindow) CloseScreen()  {
-	sdl.Quit()
-	d.Window.Destroy()
+	d.controllers.closeAll()
+	d.Texture.Destroy()
 	d.Renderer.Destroy()
+	d.Window.Destroy()
+	sdl.Quit()
 }
 
 // ReceiveInput return the current list of inputs based on their activeness
 // Returns bools based on an index using the joypad constants
-// TODO - implement mapping
 func (d *DebugWindow) GetInput() (inputs [8]bool, closeEmu bool) {
-	inputs = [8]bool{}
-	sdl.PumpEvents()
-	keys := sdl.GetKeyboardState()
-	inputs[joypad.ButtonA] = keys[sdl.SCANCODE_Z] == 1
-	inputs[joypad.ButtonB] = keys[sdl.SCANCODE_X] == 1
-	inputs[joypad.ButtonSel] = keys[sdl.SCANCODE_S] == 1
-	inputs[joypad.ButtonStart] = keys[sdl.SCANCODE_A] == 1
-
-	inputs[joypad.DpadRight] = keys[sdl.SCANCODE_RIGHT] == 1
-	inputs[joypad.DpadLeft] = keys[sdl.SCANCODE_LEFT] == 1
-	inputs[joypad.DpadUp] = keys[sdl.SCANCODE_UP] == 1
-	inputs[joypad.DpadDown] = keys[sdl.SCANCODE_DOWN] == 1
-
-	if keys[sdl.SCANCODE_R] == 1 {
-		if mainPalette == GreenPalette {
-			mainPalette = GrayPalette
-		} else {
-			mainPalette = GreenPalette
-		}
+	if pollEvents(d.controllers, d.bindings) {
+		return inputs, true
 	}
-	
-	return inputs, keys[sdl.SCANCODE_ESCAPE] == 1
+
+	keys := sdl.GetKeyboardState()
+	inputs = d.bindings.keys.readButtons(keys)
+	d.controllers.readButtons(&inputs)
+	quit := d.hotkeys.handleWindowHotkeys(d.Window, d.bindings.keys, keys)
+
+	return inputs, quit
+}
+
+// Hotkeys return the hotkeys pressed since the last call
+func (d *DebugWindow) Hotkeys() []Hotkey {
+	return d.hotkeys.take()
+}
+
+// Palette return the palette currently used to draw the screen
+func (d *DebugWindow) Palette() ppu.Palette {
+	return ActivePalette()
+}
+
+// SetPalette switch to the palette called name
+func (d *DebugWindow) SetPalette(name string) {
+	SelectPalette(name)
 }
@@ -0,0 +1,105 @@
+package window
+
+import (
+	"image/color"
+	"unicode"
+)
+
+const (
+	glyphWidth  = 3
+	glyphHeight = 5
+	maxTextLen  = (gbScreenWidth - 4) / (glyphWidth + 1) // how many characters fit across the screen
+)
+
+// font a tiny 3x5 font for drawing text over the screen, each row is 3 bits
+// with the leftmost pixel in bit 2. Lowercase letters are drawn as uppercase
+var font = map[rune][glyphHeight]byte{
+	'A':  {0b010, 0b101, 0b111, 0b101, 0b101},
+	'B':  {0b110, 0b101, 0b110, 0b101, 0b110},
+	'C':  {0b011, 0b100, 0b100, 0b100, 0b011},
+	'D':  {0b110, 0b101, 0b101, 0b101, 0b110},
+	'E':  {0b111, 0b100, 0b110, 0b100, 0b111},
+	'F':  {0b111, 0b100, 0b110, 0b100, 0b100},
+	'G':  {0b011, 0b100, 0b101, 0b101, 0b011},
+	'H':  {0b101, 0b101, 0b111, 0b101, 0b101},
+	'I':  {0b111, 0b010, 0b010, 0b010, 0b111},
+	'J':  {0b001, 0b001, 0b001, 0b101, 0b010},
+	'K':  {0b101, 0b101, 0b110, 0b101, 0b101},
+	'L':  {0b100, 0b100, 0b100, 0b100, 0b111},
+	'M':  {0b101, 0b111, 0b111, 0b101, 0b101},
+	'N':  {0b110, 0b101, 0b101, 0b101, 0b101},
+	'O':  {0b010, 0b101, 0b101, 0b101, 0b010},
+	'P':  {0b110, 0b101, 0b110, 0b100, 0b100},
+	'Q':  {0b010, 0b101, 0b101, 0b110, 0b011},
+	'R':  {0b110, 0b101, 0b110, 0b101, 0b101},
+	'S':  {0b011, 0b100, 0b010, 0b001, 0b110},
+	'T':  {0b111, 0b010, 0b010, 0b010, 0b010},
+	'U':  {0b101, 0b101, 0b101, 0b101, 0b111},
+	'V':  {0b101, 0b101, 0b101, 0b101, 0b010},
+	'W':  {0b101, 0b101, 0b111, 0b111, 0b101},
+	'X':  {0b101, 0b101, 0b010, 0b101, 0b101},
+	'Y':  {0b101, 0b101, 0b010, 0b010, 0b010},
+	'Z':  {0b111, 0b001, 0b010, 0b100, 0b111},
+	'0':  {0b111, 0b101, 0b101, 0b101, 0b111},
+	'1':  {0b010, 0b110, 0b010, 0b010, 0b111},
+	'2':  {0b110, 0b001, 0b010, 0b100, 0b111},
+	'3':  {0b110, 0b001, 0b010, 0b001, 0b110},
+	'4':  {0b101, 0b101, 0b111, 0b001, 0b001},
+	'5':  {0b111, 0b100, 0b110, 0b001, 0b110},
+	'6':  {0b011, 0b100, 0b111, 0b101, 0b111},
+	'7':  {0b111, 0b001, 0b010, 0b010, 0b010},
+	'8':  {0b111, 0b101, 0b111, 0b101, 0b111},
+	'9':  {0b111, 0b101, 0b111, 0b001, 0b110},
+	' ':  {0b000, 0b000, 0b000, 0b000, 0b000},
+	'-':  {0b000, 0b000, 0b111, 0b000, 0b000},
+	'_':  {0b000, 0b000, 0b000, 0b000, 0b111},
+	'.':  {0b000, 0b000, 0b000, 0b000, 0b010},
+	':':  {0b000, 0b010, 0b000, 0b010, 0b000},
+	'/':  {0b001, 0b001, 0b010, 0b100, 0b100},
+	'(':  {0b001, 0b010, 0b010, 0b010, 0b001},
+	')':  {0b100, 0b010, 0b010, 0b010, 0b100},
+	'+':  {0b000, 0b010, 0b111, 0b010, 0b000},
+	'!':  {0b010, 0b010, 0b010, 0b000, 0b010},
+	'?':  {0b110, 0b001, 0b010, 0b000, 0b010},
+	'\'': {0b010, 0b010, 0b000, 0b000, 0b000},
+}
+
+// drawText draw text on a box of bg at x, y in the locked texture pixels
+// Characters without a glyph are drawn as '?' and the text is cut off if it's too long
+func drawText(pixels []byte, pitch, x, y int, text string, fg, bg color.RGBA) {
+	runes := []rune(text)
+	if len(runes) > maxTextLen {
+		runes = runes[:maxTextLen]
+	}
+
+	// 1 pixel of padding around the text
+	width := len(runes)*(glyphWidth+1) + 1
+	height := glyphHeight + 2
+	for row := range height {
+		for col := range width {
+			setPixel(pixels, pitch, x+col, y+row, bg)
+		}
+	}
+
+	for i, char := range runes {
+		glyph, found := font[unicode.ToUpper(char)]
+		if !found {
+			glyph = font['?']
+		}
+
+		glyphX := x + 1 + i*(glyphWidth+1)
+		for row, bits := range glyph {
+			for col := range glyphWidth {
+				if bits&(1<<(glyphWidth-1-col)) != 0 {
+					setPixel(pixels, pitch, glyphX+col, y+1+row, fg)
+				}
+			}
+		}
+	}
+}
+
+// setPixel set the pixel at x, y in the locked texture pixels to col
+func setPixel(pixels []byte, pitch, x, y int, col color.RGBA) {
+	i := y*pitch + x*4
+	pixels[i], pixels[i+1], pixels[i+2], pixels[i+3] = col.R, col.G, col.B, col.A
+}
@@ -0,0 +1,178 @@
+package window
+
+import (
+	"fmt"
+
+	"github.com/TheOrnyx/dmg-go/config"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// Hotkey an emulator action triggered from the frontend
+type Hotkey int
+
+const (
+	HotkeyScreenshot  Hotkey = iota // save a screenshot of the current frame
+	HotkeyFullscreen                // toggle fullscreen (handled by the window itself)
+	HotkeyNextPalette               // switch to the next palette (handled by the window itself)
+	HotkeyPrevPalette               // switch to the previous palette (handled by the window itself)
+	HotkeyQuit                      // close the emulator
+)
+
+// hotkeyNames the names used in the config for each hotkey
+var hotkeyNames = map[string]Hotkey{
+	"screenshot":   HotkeyScreenshot,
+	"fullscreen":   HotkeyFullscreen,
+	"next-palette": HotkeyNextPalette,
+	"palette":      HotkeyNextPalette, // the old name for next-palette
+	"prev-palette": HotkeyPrevPalette,
+	"quit":         HotkeyQuit,
+}
+
+// keymap the keys bound to each of the joypad buttons and hotkeys
+type keymap struct {
+	buttons [8][]sdl.Scancode
+	hotkeys map[sdl.Scancode]Hotkey
+	tilt    map[string][]sdl.Scancode // tilt direction -> keys
+}
+
+// Bindings the key and controller bindings a window reads input with
+type Bindings struct {
+	keys        *keymap
+	controllers *config.Config // the config controllers get their bindings from as they're connected
+}
+
+// NewBindings build the key bindings from cfg, controllers are bound from
+// it as they're connected
+func NewBindings(cfg *config.Config) (*Bindings, error) {
+	km, err := newKeymap(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Bindings{keys: km, controllers: cfg}, nil
+}
+
+// newKeymap build a keymap from the key names in cfg
+func newKeymap(cfg *config.Config) (*keymap, error) {
+	km := &keymap{hotkeys: make(map[sdl.Scancode]Hotkey), tilt: make(map[string][]sdl.Scancode)}
+
+	for name, keys := range cfg.Buttons {
+		button, found := config.ButtonNames[name]
+		if !found {
+			return nil, fmt.Errorf("Unknown button %q", name)
+		}
+
+		for _, key := range keys {
+			code, err := scancodeFromName(key)
+			if err != nil {
+				return nil, err
+			}
+			km.buttons[button] = append(km.buttons[button], code)
+		}
+	}
+
+	for name, keys := range cfg.Hotkeys {
+		hotkey, found := hotkeyNames[name]
+		if !found {
+			return nil, fmt.Errorf("Unknown hotkey %q", name)
+		}
+
+		for _, key := range keys {
+			code, err := scancodeFromName(key)
+			if err != nil {
+				return nil, err
+			}
+			km.hotkeys[code] = hotkey
+		}
+	}
+
+	for direction, keys := range cfg.Tilt {
+		for _, key := range keys {
+			code, err := scancodeFromName(key)
+			if err != nil {
+				return nil, err
+			}
+			km.tilt[direction] = append(km.tilt[direction], code)
+		}
+	}
+
+	return km, nil
+}
+
+// scancodeFromName get the scancode for an SDL scancode name
+func scancodeFromName(name string) (sdl.Scancode, error) {
+	code := sdl.GetScancodeFromName(name)
+	if code == sdl.SCANCODE_UNKNOWN {
+		return code, fmt.Errorf("Unknown key %q", name)
+	}
+	return code, nil
+}
+
+// readButtons get the state of each joypad button from the keyboard state
+func (km *keymap) readButtons(keys []uint8) (inputs [8]bool) {
+	for button, codes := range km.buttons {
+		for _, code := range codes {
+			if keys[code] == 1 {
+				inputs[button] = true
+			}
+		}
+	}
+	return inputs
+}
+
+// hotkeyState keeps track of the hotkey keys so a hotkey only triggers
+// once per press instead of every frame it's held
+type hotkeyState struct {
+	held    map[sdl.Scancode]bool
+	pending []Hotkey // hotkeys waiting to be taken by the emulator
+}
+
+// update check the keyboard state and return the hotkeys in km that were just pressed
+func (h *hotkeyState) update(km *keymap, keys []uint8) []Hotkey {
+	if h.held == nil {
+		h.held = make(map[sdl.Scancode]bool)
+	}
+
+	var pressed []Hotkey
+	for key, hotkey := range km.hotkeys {
+		down := keys[key] == 1
+		if down && !h.held[key] {
+			pressed = append(pressed, hotkey)
+		}
+		h.held[key] = down
+	}
+
+	return pressed
+}
+
+// queue add hotkeys to the pending list for the emulator
+func (h *hotkeyState) queue(hotkeys ...Hotkey) {
+	h.pending = append(h.pending, hotkeys...)
+}
+
+// take return the pending hotkeys and clear them
+func (h *hotkeyState) take() []Hotkey {
+	pending := h.pending
+	h.pending = nil
+	return pending
+}
+
+// handleWindowHotkeys perform the hotkeys that are handled by the window
+// itself and queue the rest for the emulator. Returns whether to quit
+func (h *hotkeyState) handleWindowHotkeys(win *sdl.Window, km *keymap, keys []uint8) (quit bool) {
+	for _, hotkey := range h.update(km, keys) {
+		switch hotkey {
+		case HotkeyFullscreen:
+			toggleFullscreen(win)
+		case HotkeyNextPalette:
+			cyclePalette(1)
+		case HotkeyPrevPalette:
+			cyclePalette(-1)
+		case HotkeyQuit:
+			quit = true
+		default:
+			h.queue(hotkey)
+		}
+	}
+	return quit
+}
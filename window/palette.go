@@ -0,0 +1,74 @@
+package window
+
+import (
+	"image/color"
+	"log"
+
+	"github.com/TheOrnyx/dmg-go/ppu"
+)
+
+var GreenPalette = ppu.NewPalette("green", [4]color.RGBA{
+	{R: 155, G: 188, B: 15, A: 255},
+	{R: 139, G: 172, B: 15, A: 255},
+	{R: 48, G: 98, B: 48, A: 255},
+	{R: 15, G: 56, B: 15, A: 255},
+})
+
+var GrayPalette = ppu.NewPalette("gray", [4]color.RGBA{
+	{R: 255, G: 255, B: 255, A: 255},
+	{R: 172, G: 172, B: 172, A: 255},
+	{R: 84, G: 84, B: 84, A: 255},
+	{R: 0, G: 0, B: 0, A: 255},
+})
+
+const paletteNameFrames = 120 // how many frames the palette name is shown for after switching
+
+var palettes = []ppu.Palette{GreenPalette, GrayPalette} // the palettes that can be switched between
+var paletteIndex = 0                                    // the index of the palette in use
+var paletteNameTimer = 0                                // frames left to show the palette name for
+
+// SetPalettes add palettes to the built in ones and switch to the one named
+// start (if it exists)
+func SetPalettes(extra []ppu.Palette, start string) {
+	palettes = append([]ppu.Palette{GreenPalette, GrayPalette}, extra...)
+	paletteIndex = 0
+	SelectPalette(start)
+}
+
+// ActivePalette return the palette currently used to draw the screen
+func ActivePalette() ppu.Palette {
+	return palettes[paletteIndex]
+}
+
+// SelectPalette switch to the palette called name
+// Returns false if there's no palette with that name
+func SelectPalette(name string) bool {
+	for i, palette := range palettes {
+		if palette.Name == name {
+			paletteIndex = i
+			return true
+		}
+	}
+
+	log.Printf("No palette called %q", name)
+	return false
+}
+
+// cyclePalette move step palettes through the list, wrapping around and
+// show the new palettes name on screen
+func cyclePalette(step int) {
+	paletteIndex = (paletteIndex + step + len(palettes)) % len(palettes)
+	paletteNameTimer = paletteNameFrames
+}
+
+// drawPaletteName draw the name of the active palette in the top left of the
+// screen at x, y in the locked texture pixels if it was just switched
+func drawPaletteName(pixels []byte, pitch, x, y int) {
+	if paletteNameTimer == 0 {
+		return
+	}
+	paletteNameTimer--
+
+	palette := ActivePalette()
+	drawText(pixels, pitch, x+1, y+1, palette.Name, palette.Colors[ppu.PaletteBG][0], palette.Colors[ppu.PaletteBG][3])
+}
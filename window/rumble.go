@@ -0,0 +1,35 @@
+package window
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"time"
+)
+
+const rumbleDuration = 250           // how long a controller rumbles for in ms unless it's turned off first
+const bellInterval = time.Second / 2 // the shortest time between terminal bells
+
+// Rumbler a screen that can give rumble feedback
+type Rumbler interface {
+	Rumble(on bool) // turn the rumble on or off
+}
+
+// Rumble rumble the connected controllers, the terminal bell is rung instead
+// when there aren't any (rate limited as games pulse the motor quickly)
+func (c *Context) Rumble(on bool) {
+	if len(c.controllers) > 0 {
+		c.controllers.rumble(on)
+		return
+	}
+
+	if !on || time.Since(c.lastBell) < bellInterval {
+		return
+	}
+	if c.lastBell.IsZero() {
+		log.Println("No controller connected to rumble, ringing the terminal bell instead")
+	}
+
+	fmt.Fprint(os.Stderr, "\a")
+	c.lastBell = time.Now()
+}
@@ -0,0 +1,62 @@
+package window
+
+import (
+	"github.com/TheOrnyx/dmg-go/ppu"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// IntegerScaling whether to only scale the screen up by whole numbers
+// (when the window is big enough) rather than filling as much as possible
+var IntegerScaling = true
+
+// newScreenTexture create a streaming texture of the given size to upload frames to
+func newScreenTexture(renderer *sdl.Renderer, width, height int32) (*sdl.Texture, error) {
+	return renderer.CreateTexture(sdl.PIXELFORMAT_RGBA32, sdl.TEXTUREACCESS_STREAMING, width, height)
+}
+
+// drawLayer write layer into the locked texture pixels at x, y using palette
+func drawLayer(pixels []byte, pitch, x, y int, layer *[144][160]ppu.Pixel, palette ppu.Palette) {
+	for row := range gbScreenHeight {
+		i := (y+row)*pitch + x*4
+		for col := range gbScreenWidth {
+			c := palette.Color(layer[row][col])
+			pixels[i], pixels[i+1], pixels[i+2], pixels[i+3] = c.R, c.G, c.B, c.A
+			i += 4
+		}
+	}
+}
+
+// drawBorder write the SGB border into the locked texture pixels
+func drawBorder(pixels []byte, pitch int, border *[224][256]ppu.Pixel) {
+	for row := range border {
+		i := row * pitch
+		for _, pixel := range border[row] {
+			c := ppu.RGB15ToRGBA(pixel.RGB15)
+			pixels[i], pixels[i+1], pixels[i+2], pixels[i+3] = c.R, c.G, c.B, c.A
+			i += 4
+		}
+	}
+}
+
+// screenRect get the rect to copy a width x height texture to so it fits the
+// renderer output while keeping the aspect ratio, using whole number scales
+// if integerScale is set and the output is big enough
+func screenRect(renderer *sdl.Renderer, width, height int32, integerScale bool) *sdl.Rect {
+	outW, outH, err := renderer.GetOutputSize()
+	if err != nil {
+		return nil // just stretch over the whole output
+	}
+
+	var w, h int32
+	switch {
+	case integerScale && outW >= width && outH >= height:
+		scale := min(outW/width, outH/height)
+		w, h = width*scale, height*scale
+	case outW*height > outH*width: // output is wider than the screen
+		w, h = outH*width/height, outH
+	default:
+		w, h = outW, outW*height/width
+	}
+
+	return &sdl.Rect{X: (outW - w) / 2, Y: (outH - h) / 2, W: w, H: h}
+}
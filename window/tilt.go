@@ -0,0 +1,63 @@
+package window
+
+import "github.com/veandco/go-sdl2/sdl"
+
+// Tilter a screen that can tilt carts with an accelerometer
+type Tilter interface {
+	Tilt() (x, y float64) // get the tilt in g, positive x is tilted right and positive y is tilted down
+}
+
+// Tilt get the tilt from a controllers tilt stick, the tilt keys or
+// dragging the mouse with the left button held, in that order
+func (c *Context) Tilt() (x, y float64) {
+	if x, y, ok := c.controllers.tilt(); ok {
+		return x, y
+	}
+
+	if x, y, ok := c.bindings.keys.readTilt(sdl.GetKeyboardState()); ok {
+		return x, y
+	}
+
+	return mouseTilt(c.Window)
+}
+
+// readTilt get the tilt from the tilt keys, ok is false if none are held
+func (km *keymap) readTilt(keys []uint8) (x, y float64, ok bool) {
+	held := func(direction string) bool {
+		for _, code := range km.tilt[direction] {
+			if keys[code] == 1 {
+				return true
+			}
+		}
+		return false
+	}
+
+	if held("left") {
+		x--
+	}
+	if held("right") {
+		x++
+	}
+	if held("up") {
+		y--
+	}
+	if held("down") {
+		y++
+	}
+
+	return x, y, x != 0 || y != 0
+}
+
+// mouseTilt get the tilt from where the mouse is in the window while the left
+// button is held, the middle of the window is flat and the edges are 1g
+func mouseTilt(win *sdl.Window) (x, y float64) {
+	mouseX, mouseY, buttons := sdl.GetMouseState()
+	if buttons&sdl.ButtonLMask() == 0 {
+		return 0, 0
+	}
+
+	width, height := win.GetSize()
+	x = float64(mouseX-width/2) / float64(width/2)
+	y = float64(mouseY-height/2) / float64(height/2)
+	return max(-1, min(x, 1)), max(-1, min(y, 1))
+}
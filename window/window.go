@@ -3,8 +3,8 @@ package window
 import (
 	"log"
 	"os"
+	"time"
 
-	"github.com/TheOrnyx/dmg-go/joypad"
 	"github.com/TheOrnyx/dmg-go/ppu"
 	"github.com/veandco/go-sdl2/sdl"
 )
@@ -14,6 +14,8 @@ var FatalLog = log.New(os.Stdout, "[FaTAL] ", log.LstdFlags)
 const (
 	gbScreenWidth = 160
 	gbScreenHeight = 144
+	sgbFrameWidth = 256 // the size of the whole frame when there's an SGB border
+	sgbFrameHeight = 224
 )
 
 type Screen interface {
@@ -21,39 +23,33 @@ type Screen interface {
 	RenderScreen(screen *ppu.Screen)
 	CloseScreen()
 	GetInput() (inputs [8]bool, closeEmu bool)
+	Hotkeys() []Hotkey      // return the hotkeys pressed since the last call
+	Palette() ppu.Palette   // return the palette currently used to draw the screen
+	SetPalette(name string) // switch to the palette called name
 }
 
 type Context struct {
 	Window   *sdl.Window
 	Renderer *sdl.Renderer
+	Texture  *sdl.Texture // the streaming texture each frame is uploaded to
+	textureWidth, textureHeight int32 // the size of Texture
+	hotkeys  hotkeyState
+	bindings *Bindings
+	controllers controllerSet // the connected game controllers
+	lastBell    time.Time     // when the terminal bell was last rung for rumble
 }
 
-var GreenPalette [4]sdl.Color = [4]sdl.Color{
-	sdl.Color{R: 155, G: 188, B: 15, A: 255},
-	sdl.Color{R: 139, G: 172, B: 15, A: 255},
-	sdl.Color{R: 48, G: 98, B: 48, A: 255},
-	sdl.Color{R: 15, G: 56, B: 15, A: 255},
-}
-
-var GrayPalette [4]sdl.Color = [4]sdl.Color{
-	sdl.Color{R: 255, G: 255, B: 255, A: 255},
-	sdl.Color{R: 172, G: 172, B: 172, A: 255},
-	sdl.Color{R: 84, G: 84, B: 84, A: 255},
-	sdl.Color{R: 0, G: 0, B: 0, A: 255},
-}
-
-var mainPalette = GreenPalette
-
-
 // StartSDLWindowSystem initialize and start running the sdl windowsystem
-func InitSDLWindowSystem(width, height, scale int32) Screen {
+func InitSDLWindowSystem(width, height, scale int32, bindings *Bindings) Screen {
 	c := new(Context)
+	c.bindings = bindings
+	c.controllers = make(controllerSet)
 
 	if err := sdl.Init(sdl.INIT_EVERYTHING); err != nil {
 		FatalLog.Println("Failed to Init SDL: ", err)
 	}
 
-	win, err := sdl.CreateWindow("Gameboy-Golor", sdl.WINDOWPOS_UNDEFINED, sdl.WINDOWPOS_UNDEFINED, width, height, sdl.WINDOW_SHOWN)
+	win, err := sdl.CreateWindow("Gameboy-Golor", sdl.WINDOWPOS_UNDEFINED, sdl.WINDOWPOS_UNDEFINED, width, height, sdl.WINDOW_SHOWN|sdl.WINDOW_RESIZABLE)
 	if err != nil {
 		FatalLog.Println("Failed to Create SDL window: ", err)
 	}
@@ -64,9 +60,13 @@ func InitSDLWindowSystem(width, height, scale int32) Screen {
 		FatalLog.Println("Failed to creeate SDL renderer: ", err)
 	}
 	c.Renderer = renderer
-	if err = c.Renderer.SetScale(float32(scale), float32(scale)); err != nil {
-		log.Println("Failed to scale up Renderer, continuing anyway but might not function")
+
+	texture, err := newScreenTexture(c.Renderer, gbScreenWidth, gbScreenHeight)
+	if err != nil {
+		FatalLog.Println("Failed to create SDL screen texture: ", err)
 	}
+	c.Texture = texture
+	c.textureWidth, c.textureHeight = gbScreenWidth, gbScreenHeight
 
 	return c
 }
@@ -78,59 +78,113 @@ func (c *Context) ClearScreen() {
 }
 
 // RenderScreen render the gameboy screen to the sdl Window
+// The frame is uploaded to the texture in one go and scaled up when copied
+// With an SGB border the whole 256x224 frame is drawn with the screen in the middle
 func (c *Context) RenderScreen(screen *ppu.Screen) {
-	for drawY := 0; drawY < gbScreenHeight; drawY++ {
-		for drawX := 0; drawX < gbScreenWidth; drawX++ {
-			
-			c.Renderer.SetDrawColor(colorsFromSDLCol(mainPalette[screen.FinalScreen[drawY][drawX].Color]))
-			c.Renderer.DrawPoint(int32(drawX), int32(drawY))
-		}
+	var width, height int32 = gbScreenWidth, gbScreenHeight
+	x, y := 0, 0
+	if screen.Border != nil {
+		width, height = sgbFrameWidth, sgbFrameHeight
+		x, y = ppu.BorderScreenX, ppu.BorderScreenY
+	}
+	if err := c.resizeTexture(width, height); err != nil {
+		log.Println("Failed to resize screen texture:", err)
+		return
+	}
+
+	pixels, pitch, err := c.Texture.Lock(nil)
+	if err != nil {
+		log.Println("Failed to lock screen texture:", err)
+		return
+	}
+	if screen.Border != nil {
+		drawBorder(pixels, pitch, screen.Border)
 	}
+	drawLayer(pixels, pitch, x, y, &screen.FinalScreen, ActivePalette())
+	drawPaletteName(pixels, pitch, x, y)
+	c.Texture.Unlock()
+
+	c.Renderer.Copy(c.Texture, nil, screenRect(c.Renderer, width, height, IntegerScaling))
 	c.Renderer.Present()
 }
 
-// colorsFromSDLCol return individual colors for a given sdl color
-func colorsFromSDLCol(col sdl.Color) (r, g, b, a uint8) {
-	return col.R, col.G, col.B, col.A
+// resizeTexture recreate the screen texture if it isn't width x height
+func (c *Context) resizeTexture(width, height int32) error {
+	if c.textureWidth == width && c.textureHeight == height {
+		return nil
+	}
+
+	texture, err := newScreenTexture(c.Renderer, width, height)
+	if err != nil {
+		return err
+	}
+	c.Texture.Destroy()
+	c.Texture = texture
+	c.textureWidth, c.textureHeight = width, height
+	return nil
+}
+
+// toggleFullscreen switch win between fullscreen and windowed
+func toggleFullscreen(win *sdl.Window) {
+	var flags uint32
+	if win.GetFlags()&sdl.WINDOW_FULLSCREEN == 0 {
+		flags = sdl.WINDOW_FULLSCREEN_DESKTOP
+	}
+
+	if err := win.SetFullscreen(flags); err != nil {
+		log.Println("Failed to toggle fullscreen:", err)
+	}
 }
 
 // CloseScreen shut down the screen
 func (c *Context) CloseScreen()  {
-	sdl.Quit()
-	c.Window.Destroy()
+	c.controllers.closeAll()
+	c.Texture.Destroy()
 	c.Renderer.Destroy()
+	c.Window.Destroy()
+	sdl.Quit()
+}
+
+// pollEvents handle all the pending SDL events
+// Returns true if the window was closed
+func pollEvents(controllers controllerSet, bindings *Bindings) (quit bool) {
+	for event := sdl.PollEvent(); event != nil; event = sdl.PollEvent() {
+		switch event := event.(type) {
+		case *sdl.QuitEvent:
+			quit = true
+		case *sdl.ControllerDeviceEvent:
+			controllers.handleEvent(event, bindings.controllers)
+		}
+	}
+	return quit
 }
 
 // ReceiveInput return the current list of inputs based on their activeness
 // Returns bools based on an index using the joypad constants
-// TODO - implement mapping
 func (c *Context) GetInput() (inputs [8]bool, closeEmu bool) {
-	inputs = [8]bool{}
-	
-	event := sdl.PollEvent()
-	switch event.(type) {
-	case *sdl.QuitEvent:
+	if pollEvents(c.controllers, c.bindings) {
 		return inputs, true
 	}
 	
 	keys := sdl.GetKeyboardState()
-	inputs[joypad.ButtonA] = keys[sdl.SCANCODE_Z] == 1
-	inputs[joypad.ButtonB] = keys[sdl.SCANCODE_X] == 1
-	inputs[joypad.ButtonSel] = keys[sdl.SCANCODE_S] == 1
-	inputs[joypad.ButtonStart] = keys[sdl.SCANCODE_A] == 1
-
-	inputs[joypad.DpadRight] = keys[sdl.SCANCODE_RIGHT] == 1
-	inputs[joypad.DpadLeft] = keys[sdl.SCANCODE_LEFT] == 1
-	inputs[joypad.DpadUp] = keys[sdl.SCANCODE_UP] == 1
-	inputs[joypad.DpadDown] = keys[sdl.SCANCODE_DOWN] == 1
-
-	if keys[sdl.SCANCODE_R] == 1 {
-		if mainPalette == GreenPalette {
-			mainPalette = GrayPalette
-		} else {
-			mainPalette = GreenPalette
-		}
-	}
-	
-	return inputs, keys[sdl.SCANCODE_ESCAPE] == 1
+	inputs = c.bindings.keys.readButtons(keys)
+	c.controllers.readButtons(&inputs)
+	quit := c.hotkeys.handleWindowHotkeys(c.Window, c.bindings.keys, keys)
+
+	return inputs, quit
+}
+
+// Hotkeys return the hotkeys pressed since the last call
+func (c *Context) Hotkeys() []Hotkey {
+	return c.hotkeys.take()
+}
+
+// Palette return the palette currently used to draw the screen
+func (c *Context) Palette() ppu.Palette {
+	return ActivePalette()
+}
+
+// SetPalette switch to the palette called name
+func (c *Context) SetPalette(name string) {
+	SelectPalette(name)
 }